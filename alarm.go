@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// alarmExport processes the command (alarm export).
+// Write the timers running in the background as the events of an iCalendar
+// file, each with an alarm when the timer expires, to the file given with
+// -ics or to the standard output.
+func (cmd *Cmd) alarmExport(args []string) error {
+	if len(args) != 0 {
+		fmt.Println("Unexpected arguments to alarm export")
+		return errInvalidArgs
+	}
+
+	states, err := loadStates()
+	if err != nil {
+		return fail("Error reading the timers running in the background", err)
+	}
+	events := alarmEvents(states)
+	if len(events) == 0 {
+		fmt.Fprintln(os.Stderr, "No timer is running in the background")
+	}
+
+	var w io.Writer = os.Stdout
+	if cmd.args.ics != "" {
+		f, err := os.Create(cmd.args.ics)
+		if err != nil {
+			return fail("Error writing the alarms", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := writeICS(w, events, time.Now()); err != nil {
+		return fail("Error writing the alarms", err)
+	}
+	return nil
+}
+
+// alarmEvents returns the events of the timers running in the background
+// sorted by expiry, skipping those which did not start yet.
+func alarmEvents(states []backgroundState) []icsEvent {
+	var events []icsEvent
+	for _, s := range states {
+		if s.Ends.IsZero() {
+			continue
+		}
+		summary := s.Label
+		if summary == "" {
+			summary = tr(_msgExpired)
+		}
+		events = append(events, icsEvent{
+			uid:     fmt.Sprintf("%d-%d@timer", s.PID, s.Started.Unix()),
+			summary: summary,
+			start:   s.Ends,
+		})
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].start.Before(events[j].start) })
+	return events
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+)
+
+// expandAlias returns args with its first word replaced by the words of the
+// alias of that name in the [alias] section of the config file, if any. The
+// commands of timer cannot be shadowed by an alias, and the words of an
+// alias are not expanded again.
+func (cmd *Cmd) expandAlias(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return args, nil
+	}
+	value, ok := cmd.config.aliases[args[0]]
+	if !ok {
+		return args, nil
+	}
+	if _, ok := cmd.commands[args[0]]; ok {
+		return args, nil
+	}
+
+	words, err := splitWords(value)
+	if err != nil {
+		return nil, fmt.Errorf("alias %s: %v", args[0], err)
+	}
+	return append(words, args[1:]...), nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandAlias(t *testing.T) {
+	cmd := &Cmd{
+		config: &config{aliases: map[string]string{
+			"tea":     `-t 4m -s "Green Tea" -n`,
+			"focus":   "pomodoro -goal 8",
+			"doctor":  "-t 1m",
+			"unended": `-s "Bell`,
+		}},
+		commands: map[string]func([]string) error{"doctor": nil},
+	}
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{nil, nil},
+		{[]string{"5m"}, []string{"5m"}},
+		{[]string{"tea"}, []string{"-t", "4m", "-s", "Green Tea", "-n"}},
+		{[]string{"tea", "-volume", "30"}, []string{"-t", "4m", "-s", "Green Tea", "-n", "-volume", "30"}},
+		{[]string{"focus"}, []string{"pomodoro", "-goal", "8"}},
+		{[]string{"doctor"}, []string{"doctor"}},
+		{[]string{"-n", "tea"}, []string{"-n", "tea"}},
+	}
+
+	for _, test := range tests {
+		got, err := cmd.expandAlias(test.args)
+		if err != nil {
+			t.Errorf("%q: %v", test.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(test.want, got) {
+			t.Errorf("%q: want %q got %q", test.args, test.want, got)
+		}
+	}
+	if _, err := cmd.expandAlias([]string{"unended"}); err == nil {
+		t.Error("unended: expected error")
+	}
+}
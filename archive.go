@@ -0,0 +1,216 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	errBadArchive = errors.New("Invalid sound library archive")
+)
+
+const (
+	// directory of the sound files in a library archive
+	_archiveSoundsDir = "sounds"
+	// name of the sound metadata file in a library archive
+	_archiveMetaFile = "sounds.json"
+)
+
+// exportLibrary processes the command (sound export ARCHIVE).
+// Write all the sounds of the library and their metadata to a gzipped tar
+// archive. Linked sounds are stored in the archive like the other sounds so
+// that the archive can be imported on another machine.
+func (cmd *Cmd) exportLibrary(args []string) error {
+	if len(args) != 1 {
+		fmt.Println("Expected the archive file to export to")
+		return errInvalidArgs
+	}
+
+	f, err := os.Create(args[0])
+	if err != nil {
+		return fail("Error creating the archive", err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	meta := make(map[string]soundMeta)
+	for _, name := range cmd.matchSounds(func(string) bool { return true }) {
+		file := cmd.sounds[name]
+		entry := path.Join(_archiveSoundsDir, name+filepath.Ext(file))
+		if err := addToArchive(tw, entry, file); err != nil {
+			return fail("Error exporting the sound "+name, err)
+		}
+
+		if m, ok := cmd.meta[name]; ok && m.Command != "" {
+			meta[name] = soundMeta{Command: m.Command}
+		}
+	}
+
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{Name: _archiveMetaFile, Mode: 0644, Size: int64(len(data))}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fail("Error exporting the sound metadata", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fail("Error exporting the sound metadata", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return fail("Error writing the archive", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fail("Error writing the archive", err)
+	}
+
+	fmt.Printf("Exported %d sounds to %s\n", len(cmd.sounds), args[0])
+	return nil
+}
+
+// addToArchive writes the file to the archive under the name entry.
+func addToArchive(tw *tar.Writer, entry, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	hdr, err := tar.FileInfoHeader(fi, "")
+	if err != nil {
+		return err
+	}
+	hdr.Name = entry
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// importLibrary processes the command (sound import ARCHIVE).
+// Add the sounds and their metadata from an archive written by sound export
+// to the library. Existing sounds with the same name are replaced.
+func (cmd *Cmd) importLibrary(args []string) error {
+	if len(args) != 1 {
+		fmt.Println("Expected the archive file to import from")
+		return errInvalidArgs
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fail("Error opening the archive", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fail("Error reading the archive", err)
+	}
+	tr := tar.NewReader(gz)
+
+	imported, commands := 0, 0
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fail("Error reading the archive", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if hdr.Name == _archiveMetaFile {
+			if commands, err = importMeta(tr); err != nil {
+				return fail("Error importing the sound metadata", err)
+			}
+			continue
+		}
+
+		rel := strings.TrimPrefix(hdr.Name, _archiveSoundsDir+"/")
+		name := strings.TrimSuffix(rel, path.Ext(rel))
+		if rel == hdr.Name || !validSoundName(name) {
+			fmt.Println("Invalid entry in the archive:", hdr.Name)
+			return errBadArchive
+		}
+
+		if err := extractSound(tr, cmd.soundFile(name, path.Ext(rel)), hdr); err != nil {
+			return fail("Error importing the sound "+name, err)
+		}
+		imported++
+	}
+
+	if err := cmd.saveLibrary(); err != nil {
+		return fail("Error saving the sound metadata", err)
+	}
+
+	fmt.Printf("Imported %d sounds from %s\n", imported, args[0])
+	if commands > 0 {
+		fmt.Printf("The player commands of %d sounds were not imported, set them again with -soundcmd\n", commands)
+	}
+	return nil
+}
+
+// importMeta reads the sound metadata of an archive from r and returns the
+// number of sounds with a player command. The commands are not imported, as
+// they would run whatever the archive says, and the archive has no other
+// metadata.
+func importMeta(r io.Reader) (int, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+
+	meta := make(map[string]soundMeta)
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return 0, err
+	}
+	commands := 0
+	for _, m := range meta {
+		if m.Command != "" {
+			commands++
+		}
+	}
+	return commands, nil
+}
+
+// extractSound writes the content of the current archive entry to file.
+func extractSound(r io.Reader, file string, hdr *tar.Header) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0776); err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, r)
+	if e := out.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Chtimes(file, hdr.ModTime, hdr.ModTime)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLibraryArchive(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	src := &Cmd{soundsDir: filepath.Join(dir, "src"), sounds: make(map[string]string)}
+	linked := filepath.Join(dir, "linked.mp3")
+	for name, file := range map[string]string{
+		"Bell":       src.soundFile("Bell", ".wav"),
+		"nature/Owl": src.soundFile("nature/Owl", ".ogg"),
+		"Linked":     linked,
+	} {
+		os.MkdirAll(filepath.Dir(file), 0776)
+		if err := os.WriteFile(file, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		src.sounds[name] = file
+	}
+	src.meta = map[string]soundMeta{
+		"Bell":   {Command: "paplay {file}"},
+		"Linked": {Path: linked},
+	}
+	archive := filepath.Join(dir, "sounds.tar.gz")
+	if err := src.exportLibrary([]string{archive}); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := &Cmd{soundsDir: filepath.Join(dir, "dst"), meta: map[string]soundMeta{
+		"Kept": {Command: "aplay {file}"},
+	}}
+	if err := dst.importLibrary([]string{archive}); err != nil {
+		t.Fatal(err)
+	}
+	for name, ext := range map[string]string{"Bell": ".wav", "nature/Owl": ".ogg", "Linked": ".mp3"} {
+		if data, err := os.ReadFile(dst.soundFile(name, ext)); err != nil || string(data) != name {
+			t.Errorf("sound %s: want %q got %q, %v", name, name, data, err)
+		}
+	}
+	// Linked sounds are imported as copies, and the player commands of the
+	// archive are not imported
+	want := map[string]soundMeta{"Kept": {Command: "aplay {file}"}}
+	if !reflect.DeepEqual(dst.meta, want) {
+		t.Errorf("metadata: want %+v got %+v", want, dst.meta)
+	}
+	if meta, err := loadLibrary(getLibraryFile(dst.soundsDir)); err != nil || !reflect.DeepEqual(meta, want) {
+		t.Errorf("saved metadata: want %+v got %+v, %v", want, meta, err)
+	}
+}
+
+func TestImportBadArchive(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	write := func(headers ...*tar.Header) string {
+		f, err := os.CreateTemp(dir, "*.tar.gz")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		gz := gzip.NewWriter(f)
+		tw := tar.NewWriter(gz)
+		for _, hdr := range headers {
+			tw.WriteHeader(hdr)
+			tw.Write(make([]byte, hdr.Size))
+		}
+		tw.Close()
+		gz.Close()
+		return f.Name()
+	}
+
+	// Only regular files are imported, links and directories are skipped
+	cmd := &Cmd{soundsDir: filepath.Join(dir, "sounds"), meta: make(map[string]soundMeta)}
+	archive := write(
+		&tar.Header{Name: "sounds/", Typeflag: tar.TypeDir, Mode: 0755},
+		&tar.Header{Name: "sounds/Passwd.wav", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"},
+		&tar.Header{Name: "sounds/Bell.wav", Typeflag: tar.TypeReg, Mode: 0644, Size: 4},
+	)
+	if err := cmd.importLibrary([]string{archive}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Lstat(cmd.soundFile("Passwd", ".wav")); !os.IsNotExist(err) {
+		t.Errorf("symbolic link imported: %v", err)
+	}
+	if _, err := os.Stat(cmd.soundFile("Bell", ".wav")); err != nil {
+		t.Errorf("regular file not imported: %v", err)
+	}
+
+	// The player command of a sound is not taken from the archive
+	f, err := os.Create(filepath.Join(dir, "commands.tar.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	meta := []byte(`{"Bell": {"command": "rm -rf ~ {file}"}}`)
+	tw.WriteHeader(&tar.Header{Name: "sounds/Bell.wav", Typeflag: tar.TypeReg, Mode: 0644, Size: 4})
+	tw.Write(make([]byte, 4))
+	tw.WriteHeader(&tar.Header{Name: _archiveMetaFile, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(meta))})
+	tw.Write(meta)
+	tw.Close()
+	gz.Close()
+	f.Close()
+	if err := cmd.importLibrary([]string{f.Name()}); err != nil {
+		t.Fatal(err)
+	}
+	if m, ok := cmd.meta["Bell"]; ok {
+		t.Errorf("player command imported: %+v", m)
+	}
+
+	for _, name := range []string{"sounds/../../evil.wav", "sounds/a\\b.wav", "Bell.wav"} {
+		archive := write(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: 4})
+		if err := cmd.importLibrary([]string{archive}); err != errBadArchive {
+			t.Errorf("entry %s: want %v got %v", name, errBadArchive, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "evil.wav")); !os.IsNotExist(err) {
+		t.Errorf("entry written outside the sounds directory: %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	errNoFfmpeg = errors.New("ffmpeg is required but not installed")
+)
+
+// _headerSize is the number of leading bytes of a file needed to detect its
+// audio format.
+const _headerSize = 12
+
+// detectFormat returns the audio format of the file with the given leading
+// bytes, or an empty string if the format is not recognized.
+func detectFormat(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, []byte("RIFF")) && len(header) >= 12 && string(header[8:12]) == "WAVE":
+		return "wav"
+	case bytes.HasPrefix(header, []byte("ID3")):
+		return "mp3"
+	case len(header) >= 2 && header[0] == 0xff && header[1]&0xe0 == 0xe0:
+		// MPEG frame sync, also used by ADTS AAC
+		if header[1]&0x06 == 0 {
+			return "aac"
+		}
+		return "mp3"
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return "ogg"
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return "flac"
+	case bytes.HasPrefix(header, []byte("MThd")):
+		return "midi"
+	case bytes.HasPrefix(header, []byte("FORM")) && len(header) >= 12 &&
+		(string(header[8:12]) == "AIFF" || string(header[8:12]) == "AIFC"):
+		return "aiff"
+	case len(header) >= 8 && string(header[4:8]) == "ftyp":
+		return "m4a"
+	case bytes.HasPrefix(header, []byte{0x30, 0x26, 0xb2, 0x75}):
+		return "wma"
+	}
+	return ""
+}
+
+// fileFormat returns the audio format of the file, or an empty string if the
+// format is not recognized.
+func fileFormat(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	header := make([]byte, _headerSize)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	return detectFormat(header[:n]), nil
+}
+
+// soundDuration returns the play time of the sound file. WAV files are read
+// natively, other formats need ffprobe to be installed. Zero is returned if
+// the duration can not be found.
+func soundDuration(file string) time.Duration {
+	if d, err := wavDuration(file); err == nil {
+		return d
+	}
+
+	if _, err := exec.LookPath("ffprobe"); err != nil {
+		return 0
+	}
+	out, err := exec.Command("ffprobe", "-v", "error", "-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1", file).Output()
+	if err != nil {
+		return 0
+	}
+	secs, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(secs * float64(time.Second))
+}
+
+// wavDuration returns the play time of the WAV file reading only its headers.
+func wavDuration(file string) (time.Duration, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return 0, err
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return 0, errNotWav
+	}
+
+	byteRate := 0
+	chunk := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(f, chunk); err != nil {
+			return 0, err
+		}
+		size := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+		// Chunks are padded to an even size
+		skip := size + size%2
+
+		switch string(chunk[0:4]) {
+		case "fmt ":
+			if size < 12 || size > 1024 {
+				return 0, errNotWav
+			}
+			fmtChunk := make([]byte, size)
+			if _, err := io.ReadFull(f, fmtChunk); err != nil {
+				return 0, err
+			}
+			byteRate = int(binary.LittleEndian.Uint32(fmtChunk[8:12]))
+			skip = size % 2
+		case "data":
+			if byteRate == 0 {
+				return 0, errNotWav
+			}
+			return time.Duration(float64(size) / float64(byteRate) * float64(time.Second)), nil
+		}
+
+		if _, err := f.Seek(skip, io.SeekCurrent); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// normalizeSound converts the audio file in to the 44.1kHz 16 bit WAV
+// file out with its loudness normalized, using ffmpeg.
+func normalizeSound(in, out string) error {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return errNoFfmpeg
+	}
+
+	ex := exec.Command("ffmpeg", "-y", "-loglevel", "error", "-i", in,
+		"-af", "loudnorm", "-ar", "44100", "-c:a", "pcm_s16le", out)
+	if output, err := ex.CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %v: %s", err, bytes.TrimSpace(output))
+	}
+	return nil
+}
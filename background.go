@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// _timerBackground is the name of the environment variable set for a
+	// timer started in the background by -background.
+	_timerBackground = "TIMER_BACKGROUND"
+	// time a looping sound of a timer in the background plays unless
+	// -sound-timeout is given, no key can be pressed to stop it
+	_backgroundLoopTimeout = 5 * time.Minute
+)
+
+// backgroundState is the state file of a timer running in the background.
+type backgroundState struct {
+	PID     int       `json:"pid"`
+	Args    []string  `json:"args"`
+	Started time.Time `json:"started"`
+	// label and expiry of the running timer, once it started
+	Label string    `json:"label,omitempty"`
+	Ends  time.Time `json:"ends,omitempty"`
+}
+
+// getStateDir returns the directory storing the state files of the timers
+// running in the background, one per process named after its PID.
+func getStateDir() string {
+	return filepath.Join(getConfigDir(), "background")
+}
+
+// background processes -background.
+// Start the same command again detached from the terminal and return right
+// away, the timer in the background shows the notification and plays the
+// sound when it expires.
+func (cmd *Cmd) background() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fail("Error starting the timer in the background", err)
+	}
+	null, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+	if err != nil {
+		return fail("Error starting the timer in the background", err)
+	}
+	defer null.Close()
+
+	ex := cmd.backgroundCommand(exe, os.Args[1:], os.Environ())
+	ex.Stdin, ex.Stdout, ex.Stderr = null, null, null
+	detachProcess(ex)
+	if err := ex.Start(); err != nil {
+		return fail("Error starting the timer in the background", err)
+	}
+	fmt.Printf("Timer running in the background with PID %d\n", ex.Process.Pid)
+	return ex.Process.Release()
+}
+
+// backgroundCommand returns the command starting the timer of args again
+// in the background with the environment env. -loop-sound is bounded by
+// _backgroundLoopTimeout through the environment, which keeps args intact
+// for aliases and --.
+func (cmd *Cmd) backgroundCommand(exe string, args, env []string) *exec.Cmd {
+	ex := exec.Command(exe, args...)
+	ex.Env = append(env[:len(env):len(env)], _timerBackground+"=1")
+	if cmd.args.loopSound && cmd.args.soundTimeout == 0 {
+		ex.Env = append(ex.Env, envName("sound-timeout")+"="+_backgroundLoopTimeout.String())
+	}
+	return ex
+}
+
+// writeState writes the state file of the timer running in the background
+// and returns its location.
+func writeState() (string, error) {
+	if err := os.MkdirAll(getStateDir(), 0776); err != nil {
+		return "", err
+	}
+	file := filepath.Join(getStateDir(), fmt.Sprintf("%d.json", os.Getpid()))
+	return file, saveState(file, backgroundState{PID: os.Getpid(), Args: os.Args[1:], Started: time.Now()})
+}
+
+// saveState writes the state to the state file.
+func saveState(file string, state backgroundState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, append(data, '\n'), 0644)
+}
+
+// updateState records the label and the expiry of the timer running in the
+// background in its state file, if it has one.
+func (cmd *Cmd) updateState(end time.Time) {
+	if cmd.stateFile == "" {
+		return
+	}
+	data, err := ioutil.ReadFile(cmd.stateFile)
+	var state backgroundState
+	if err == nil {
+		err = json.Unmarshal(data, &state)
+	}
+	if err == nil {
+		state.Label, state.Ends = cmd.label, end
+		err = saveState(cmd.stateFile, state)
+	}
+	if err != nil {
+		slog.Warn("updating the state file failed", "err", err)
+	}
+}
+
+// loadStates returns the states of the timers running in the background.
+// The state files of timers which expired, left by a timer which was killed,
+// are skipped.
+func loadStates() ([]backgroundState, error) {
+	files, err := filepath.Glob(filepath.Join(getStateDir(), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var states []backgroundState
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var state backgroundState
+		if err := json.Unmarshal(data, &state); err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		if !state.Ends.IsZero() && state.Ends.Before(time.Now()) {
+			continue
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBackgroundCommand(t *testing.T) {
+	args := []string{"tea", "-s", "Bell", "--", "5m"}
+	env := []string{"HOME=/home/timer", "TIMER_VOLUME=40"}
+
+	cmd := &Cmd{}
+	ex := cmd.backgroundCommand("/usr/bin/timer", args, env)
+	if want := append([]string{"/usr/bin/timer"}, args...); !reflect.DeepEqual(ex.Args, want) {
+		t.Errorf("args: want %q got %q", want, ex.Args)
+	}
+	if want := append(env, _timerBackground+"=1"); !reflect.DeepEqual(ex.Env, want) {
+		t.Errorf("env: want %q got %q", want, ex.Env)
+	}
+
+	// A looping sound is bounded unless -sound-timeout is given
+	cmd.args.loopSound = true
+	ex = cmd.backgroundCommand("/usr/bin/timer", args, env)
+	want := append(env, _timerBackground+"=1", "TIMER_SOUND_TIMEOUT=5m0s")
+	if !reflect.DeepEqual(ex.Env, want) {
+		t.Errorf("-loop-sound env: want %q got %q", want, ex.Env)
+	}
+	cmd.args.soundTimeout = time.Minute
+	ex = cmd.backgroundCommand("/usr/bin/timer", args, env)
+	if want := append(env, _timerBackground+"=1"); !reflect.DeepEqual(ex.Env, want) {
+		t.Errorf("-loop-sound -sound-timeout env: want %q got %q", want, ex.Env)
+	}
+	if len(env) != 2 {
+		t.Errorf("environment of the process changed: %q", env)
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	errEmptyBatch = errors.New("No timers in batch")
+)
+
+// batchTimer is a timer of a batch, read from a line DURATION LABEL [SOUND],
+// or a step of a sequence.
+type batchTimer struct {
+	duration time.Duration
+	label    string
+	sound    string
+	// show a notification when the timer expires even without -notify
+	notify bool
+}
+
+// parseBatch reads the timers of a batch, one per line. Lines are split into
+// words like a shell does, so that labels with spaces can be quoted. Empty
+// lines and lines starting with # are skipped.
+func parseBatch(r io.Reader, unit time.Duration) ([]batchTimer, error) {
+	var timers []batchTimer
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		words, err := splitWords(line)
+		if err != nil {
+			return nil, fmt.Errorf("batch line %d: %v", n, err)
+		}
+		if len(words) < 2 || len(words) > 3 {
+			return nil, fmt.Errorf("batch line %d: expected DURATION LABEL [SOUND]", n)
+		}
+		d, err := parseTime(words[0], unit)
+		if err != nil {
+			return nil, fmt.Errorf("batch line %d: %v", n, err)
+		}
+
+		timer := batchTimer{duration: d, label: words[1]}
+		if len(words) == 3 {
+			timer.sound = words[2]
+		}
+		timers = append(timers, timer)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return timers, nil
+}
+
+// batch processes the command (batch).
+// Run the timers listed in the file given with -file, or read from standard
+// input, one after the other or with -parallel all at the same time. A timer
+// without a sound of its own plays the sound given with -sound.
+func (cmd *Cmd) batch(args []string) error {
+	if len(args) != 0 {
+		fmt.Println("Unexpected arguments to batch, give the file with -f")
+		return errInvalidArgs
+	}
+
+	var r io.Reader = os.Stdin
+	if cmd.args.file != "" && cmd.args.file != "-" {
+		f, err := os.Open(cmd.args.file)
+		if err != nil {
+			return fail("Error opening the batch file", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	timers, err := parseBatch(r, cmd.config.defaultUnit)
+	if err != nil {
+		fmt.Println("Error reading the batch:", err)
+		return err
+	}
+	if len(timers) == 0 {
+		fmt.Println(errEmptyBatch)
+		return errEmptyBatch
+	}
+
+	if err := cmd.selectBatchSounds(timers, cmd.args.sound); err != nil {
+		return err
+	}
+	if cmd.args.parallel {
+		return cmd.batchParallel(timers)
+	}
+	return cmd.batchSequential(timers)
+}
+
+// selectBatchSounds selects the sounds of the timers, so that all of them
+// are known to be there before the first timer starts. Timers without a
+// sound of their own get sound, or the default sound with -with-sound.
+func (cmd *Cmd) selectBatchSounds(timers []batchTimer, sound string) error {
+	if cmd.args.withSound && sound == "" {
+		sound = _defaultSoundName
+	}
+	for i := range timers {
+		if timers[i].sound == "" {
+			timers[i].sound = sound
+		}
+		if timers[i].sound == "" {
+			continue
+		}
+		var err error
+		if timers[i].sound, err = cmd.selectSound(timers[i].sound); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// batchSequential runs the timers one after the other, telling which one
+// comes next when a timer expires.
+func (cmd *Cmd) batchSequential(timers []batchTimer) error {
+	for i, timer := range timers {
+		fmt.Printf("[%d/%d] %s\n", i+1, len(timers), timer.label)
+		cmd.label = timer.label
+		if err := cmd.countdown(timer.duration); err != nil {
+			return err
+		}
+
+		text := tr(_msgNotifyText)
+		if i+1 < len(timers) {
+			text = fmt.Sprintf(tr(_msgNext), timers[i+1].label)
+			fmt.Println(text)
+		}
+		if err := cmd.batchAlert(timer, text, !cmd.args.waitSound); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// batchParallel runs all the timers at the same time, showing the remaining
+// time of the running ones on a single line.
+func (cmd *Cmd) batchParallel(timers []batchTimer) error {
+	sort.SliceStable(timers, func(i, j int) bool {
+		return timers[i].duration < timers[j].duration
+	})
+
+	start := time.Now()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for _, timer := range timers {
+		cmd.label = timer.label
+		cmd.logEvent("started, %v until %s", timer.duration, start.Add(timer.duration).Format("15:04:05"))
+	}
+
+	cmd.batchProgress(timers, 0)
+	for len(timers) > 0 {
+		expired := time.NewTimer(time.Until(start.Add(timers[0].duration)))
+		select {
+		case <-ticker.C:
+			cmd.batchProgress(timers, time.Since(start))
+		case <-expired.C:
+			if cmd.ansi {
+				fmt.Printf("\r\x1b[K⏰  %s\n", timers[0].label)
+			} else {
+				fmt.Printf("\r%-79s\n", timers[0].label)
+			}
+			cmd.label = timers[0].label
+			cmd.started, cmd.expired = start, time.Now()
+			cmd.logEvent("expired after %v", timers[0].duration)
+			cmd.sendHooks(_statusExpired, start, start.Add(timers[0].duration))
+			if err := cmd.batchAlert(timers[0], timers[0].label, true); err != nil {
+				return err
+			}
+			timers = timers[1:]
+			cmd.batchProgress(timers, time.Since(start))
+		case <-cmd.ctx.Done():
+			expired.Stop()
+			for _, timer := range timers {
+				cmd.label = timer.label
+				cmd.logEvent("interrupted after %v", time.Since(start).Truncate(time.Second))
+				cmd.sendHooks(_statusCancelled, start, start.Add(timer.duration))
+			}
+			fmt.Println("\n" + tr(_msgInterrupted))
+			return errInterrupted
+		}
+		expired.Stop()
+	}
+	return nil
+}
+
+// batchProgress shows the label and the remaining time of the running timers
+// on the current line.
+func (cmd *Cmd) batchProgress(timers []batchTimer, passed time.Duration) {
+	if len(timers) == 0 {
+		return
+	}
+
+	parts := make([]string, len(timers))
+	for i, timer := range timers {
+		// The remaining time is rounded up so that it only reaches 0 on expiry
+		remaining := timer.duration - passed
+		if r := remaining.Truncate(time.Second); r < remaining {
+			remaining = r + time.Second
+		}
+		parts[i] = fmt.Sprintf("%s: %v", timer.label, remaining)
+	}
+
+	line := strings.Join(parts, ", ")
+	if cmd.ansi {
+		fmt.Printf("\r\x1b[K⏲  %s", line)
+		return
+	}
+	fmt.Printf("\r%-79s", line)
+}
+
+// batchAlert shows the notification with the text if -notify is given or
+// the expired timer asks for one, and plays the sound of the timer.
+func (cmd *Cmd) batchAlert(timer batchTimer, text string, background bool) error {
+	if cmd.args.notify || timer.notify {
+		e := cmd.event(_statusExpired, cmd.started, cmd.expired)
+		e.Message = text
+		e = cmd.notifyText(e)
+		if err := cmd.sendNotification(e); err != nil {
+			return withExitCode(_exitNotify, fail("Error showing notification", err))
+		}
+	}
+	if timer.sound == "" {
+		return nil
+	}
+	cmd.args.sound = timer.sound
+	return withExitCode(_exitSound, cmd.ringSound(background))
+}
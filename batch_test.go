@@ -0,0 +1,32 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseBatch(t *testing.T) {
+	in := `# agenda
+10m Welcome
+
+"25:00" "Status update" Bell
+5 Questions nature/Rain
+`
+	want := []batchTimer{
+		{10 * time.Minute, "Welcome", "", false},
+		{25 * time.Minute, "Status update", "Bell", false},
+		{5 * time.Minute, "Questions", "nature/Rain", false},
+	}
+	got, err := parseBatch(strings.NewReader(in), time.Minute)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v got %v, %v", want, got, err)
+	}
+
+	for _, in := range []string{"10m", "ten Welcome", "10m Welcome Bell extra", `10m "Welcome`} {
+		if _, err := parseBatch(strings.NewReader(in), time.Minute); err == nil {
+			t.Errorf("%s: want an error", in)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	errNotTerminal = errors.New("Standard input is not a terminal")
+)
+
+const (
+	// how long a sound is played when previewed in the sound browser
+	_browsePreview = 5 * time.Second
+	// number of sounds shown at once in the sound browser
+	_browsePageSize = 20
+	// ANSI sequence clearing the screen and moving the cursor to the top
+	_clearScreen = "\x1b[H\x1b[2J"
+)
+
+// Keys read from the terminal in the sound browser
+const (
+	_keyUp        = "\x1b[A"
+	_keyDown      = "\x1b[B"
+	_keyEnter     = "\r"
+	_keyNewline   = "\n"
+	_keyBackspace = "\x7f"
+	_keyEscape    = "\x1b"
+)
+
+// browser is the state of the interactive sound browser.
+type browser struct {
+	cmd    *Cmd
+	names  []string
+	cursor int
+	// first name shown on the screen
+	top int
+	// message shown below the list
+	status string
+	// stops the sound being previewed
+	stop context.CancelFunc
+}
+
+// browseSounds processes the command (sound browse).
+// Show the sound library in the terminal. The arrow keys or j and k move
+// through the sounds, Enter previews the selected sound, d deletes it, r
+// renames it and q quits.
+func (cmd *Cmd) browseSounds(args []string) error {
+	if len(args) != 0 {
+		fmt.Println("Unexpected arguments to sound browse")
+		return errInvalidArgs
+	}
+
+	if !cmd.ansi {
+		fmt.Println("The sound browser needs a terminal supporting ANSI escape sequences")
+		return errNotTerminal
+	}
+
+	restore, err := rawInput(os.Stdin.Fd())
+	if err != nil {
+		fmt.Println("The sound browser needs a terminal")
+		return errNotTerminal
+	}
+	defer restore()
+
+	b := &browser{cmd: cmd, stop: func() {}}
+	b.reload()
+	defer b.stop()
+
+	keys := make(chan string)
+	go readKeys(keys)
+
+	for {
+		b.draw()
+
+		var (
+			key string
+			ok  bool
+		)
+		select {
+		case key, ok = <-keys:
+			if !ok {
+				fmt.Print(_clearScreen)
+				return nil
+			}
+		case <-cmd.ctx.Done():
+			fmt.Print(_clearScreen)
+			return nil
+		}
+
+		b.stop()
+		b.status = ""
+		switch key {
+		case _keyUp, "k":
+			b.move(-1)
+		case _keyDown, "j":
+			b.move(1)
+		case _keyEnter, _keyNewline:
+			b.preview()
+		case "d":
+			b.delete(keys)
+		case "r":
+			b.rename(keys)
+		case "q", _keyEscape:
+			fmt.Print(_clearScreen)
+			return nil
+		}
+	}
+}
+
+// readKeys sends the keys pressed on the terminal to keys. An escape
+// sequence, like the one of an arrow key, arrives in a single read and is
+// sent as one key.
+func readKeys(keys chan<- string) {
+	buf := make([]byte, 16)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		keys <- string(buf[:n])
+	}
+}
+
+// reload refreshes the sorted names of the sounds and keeps the cursor in
+// the list.
+func (b *browser) reload() {
+	b.names = b.cmd.matchSounds(func(string) bool { return true })
+	if b.cursor >= len(b.names) {
+		b.cursor = len(b.names) - 1
+	}
+	if b.cursor < 0 {
+		b.cursor = 0
+	}
+	b.move(0)
+}
+
+// move moves the cursor by n sounds and scrolls the list to show it.
+func (b *browser) move(n int) {
+	b.cursor += n
+	if b.cursor < 0 {
+		b.cursor = 0
+	}
+	if b.cursor >= len(b.names) {
+		b.cursor = len(b.names) - 1
+	}
+
+	if b.cursor < b.top {
+		b.top = b.cursor
+	}
+	if b.cursor >= b.top+_browsePageSize {
+		b.top = b.cursor - _browsePageSize + 1
+	}
+	if b.top < 0 {
+		b.top = 0
+	}
+}
+
+// draw shows the visible part of the list, the selected sound and the
+// status message.
+func (b *browser) draw() {
+	var s strings.Builder
+	s.WriteString(_clearScreen)
+	s.WriteString(tr(_msgBrowseHeader) + "\n\n")
+
+	if len(b.names) == 0 {
+		s.WriteString("  No sounds in the library\n")
+	}
+	for i := b.top; i < len(b.names) && i < b.top+_browsePageSize; i++ {
+		if i == b.cursor {
+			fmt.Fprintf(&s, "> %s\n", b.names[i])
+		} else {
+			fmt.Fprintf(&s, "  %s\n", b.names[i])
+		}
+	}
+
+	if b.status != "" {
+		fmt.Fprintf(&s, "\n%s\n", b.status)
+	}
+	fmt.Print(s.String())
+}
+
+// selected returns the name of the sound under the cursor.
+func (b *browser) selected() (string, bool) {
+	if len(b.names) == 0 {
+		return "", false
+	}
+	return b.names[b.cursor], true
+}
+
+// preview starts playing the selected sound for at most _browsePreview. The
+// next key pressed stops it.
+func (b *browser) preview() {
+	name, ok := b.selected()
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(b.cmd.ctx, _browsePreview)
+	b.stop = cancel
+	b.status = "Playing " + name
+	go b.cmd.play(ctx, name, b.cmd.sounds[name])
+}
+
+// delete deletes the selected sound after confirmation.
+func (b *browser) delete(keys <-chan string) {
+	name, ok := b.selected()
+	if !ok {
+		return
+	}
+
+	b.status = fmt.Sprintf("Delete %s? (y/n)", name)
+	b.draw()
+	if key := <-keys; key != "y" && key != "Y" {
+		b.status = ""
+		return
+	}
+
+	b.cmd.args.deleteSound = name
+	if err := b.cmd.deleteSound(); err != nil {
+		b.status = fmt.Sprintf("Error deleting %s: %v", name, err)
+		return
+	}
+	delete(b.cmd.sounds, name)
+	b.reload()
+	b.status = "Deleted " + name
+}
+
+// rename asks for a new name for the selected sound and renames it.
+func (b *browser) rename(keys <-chan string) {
+	name, ok := b.selected()
+	if !ok {
+		return
+	}
+
+	newName, ok := b.readLine(keys, fmt.Sprintf("Rename %s to: ", name))
+	if !ok || newName == "" {
+		b.status = ""
+		return
+	}
+
+	if err := b.cmd.renameSound([]string{name, newName}); err != nil {
+		b.status = fmt.Sprintf("Error renaming %s: %v", name, err)
+		return
+	}
+
+	file := b.cmd.sounds[name]
+	if b.cmd.meta[newName].Path == "" {
+		file = b.cmd.soundFile(newName, filepath.Ext(file))
+	}
+	delete(b.cmd.sounds, name)
+	b.cmd.sounds[newName] = file
+	b.reload()
+	for i, n := range b.names {
+		if n == newName {
+			b.cursor = i
+		}
+	}
+	b.move(0)
+	b.status = fmt.Sprintf("Renamed %s to %s", name, newName)
+}
+
+// readLine reads a line typed after prompt. It returns false if editing was
+// cancelled with Escape.
+func (b *browser) readLine(keys <-chan string, prompt string) (string, bool) {
+	var line []rune
+	for {
+		b.status = prompt + string(line)
+		b.draw()
+
+		key, ok := <-keys
+		if !ok {
+			return "", false
+		}
+		switch key {
+		case _keyEnter, _keyNewline:
+			return strings.TrimSpace(string(line)), true
+		case _keyEscape:
+			return "", false
+		case _keyBackspace, "\b":
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+			}
+		default:
+			if !strings.HasPrefix(key, _keyEscape) {
+				line = append(line, []rune(key)...)
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+//go:build freebsd || openbsd || netbsd || dragonfly
+// +build freebsd openbsd netbsd dragonfly
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// _statusSignals are the signals asking a running timer for its status,
+// SIGINFO is sent by Ctrl-T.
+var _statusSignals = []os.Signal{syscall.SIGUSR1, syscall.SIGINFO}
+
+// _suspendSignals are the signals suspending the process, like Ctrl-Z.
+var _suspendSignals = []os.Signal{syscall.SIGTSTP}
+
+// _soundPlayers is the list of commands probed in order to find an
+// available audio player.
+var _soundPlayers = []string{
+	"ffplay -nodisp -autoexit -loglevel quiet -volume {volume} {file}",
+	"mpv --no-video --really-quiet --volume={volume} {file}",
+	"play -q {file}",
+	"aucat -i {file}",
+	"audioplay {file}",
+}
+
+// _thenCommands are the commands of the actions of -then, probed in order
+// to find an available one.
+var _thenCommands = map[string][]string{
+	"lock":     {"xdg-screensaver lock", "xlock"},
+	"suspend":  {"zzz", "acpiconf -s 3"},
+	"shutdown": {"shutdown -p now"},
+	// the backlight, restored to the full brightness
+	"dim":     {"backlight {level}"},
+	"restore": {"backlight 100"},
+}
+
+// getConfigDir returns the directory storing the configuration.
+func getConfigDir() string {
+	return filepath.Join(os.Getenv("HOME"), ".config", "timer")
+}
+
+// getSoundsDir returns the directory storing added sounds.
+func getSoundsDir() string {
+	return filepath.Join(os.Getenv("HOME"), ".config", "timer", "sounds")
+}
+
+// migrateLegacyDirs moves files from the locations used by earlier versions.
+// There are no earlier versions on the BSDs.
+func migrateLegacyDirs() {}
+
+// rawInput disables line buffering and echo on the terminal fd so that a
+// single keypress can be read. The returned function restores the terminal.
+func rawInput(fd uintptr) (func(), error) {
+	termios, err := unix.IoctlGetTermios(int(fd), unix.TIOCGETA)
+	if err != nil {
+		return nil, err
+	}
+
+	old := *termios
+	termios.Lflag &^= unix.ECHO | unix.ICANON
+	termios.Cc[unix.VMIN] = 1
+	termios.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(int(fd), unix.TIOCSETA, termios); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		unix.IoctlSetTermios(int(fd), unix.TIOCSETA, &old)
+	}, nil
+}
+
+// enableVirtualTerminal reports whether ANSI escape sequences are handled,
+// which terminals always do.
+func enableVirtualTerminal() bool {
+	return true
+}
+
+// systemLocale returns the locale of the system when none is set in the
+// environment, which on Unix means the C locale.
+func systemLocale() string {
+	return ""
+}
+
+// setProcessGroup makes the command run in its own process group so that
+// the process and its children can be killed together.
+func setProcessGroup(ex *exec.Cmd) {
+	ex.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// detachProcess makes the command run detached from the terminal, so that it
+// keeps running after the terminal is closed.
+func detachProcess(ex *exec.Cmd) {
+	ex.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// killProcessGroup kills the process group led by the process p.
+func killProcessGroup(p *os.Process) error {
+	return syscall.Kill(-p.Pid, syscall.SIGKILL)
+}
+
+// stopProcess stops the process like the default action of SIGTSTP would,
+// it returns once the process is continued.
+func stopProcess() error {
+	return syscall.Kill(os.Getpid(), syscall.SIGSTOP)
+}
+
+// isExecutable reports whether the file can be run, by its permissions.
+func isExecutable(info os.FileInfo) bool {
+	return info.Mode()&0111 != 0
+}
+
+// showNotification shows a desktop notification with notify-send. The D-Bus
+// library used for notifications on Linux does not build on the BSDs.
+func showNotification(title, message string) error {
+	return exec.Command("notify-send", title, message).Run()
+}
+
+// windowsPath returns the location of the file as a Windows path. There is
+// no Windows side on the BSDs, the location is returned unchanged.
+func windowsPath(file string) string {
+	return file
+}
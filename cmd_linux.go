@@ -1,11 +1,188 @@
 package main
 
 import (
+	"fmt"
+	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/gen2brain/beeep"
+	"golang.org/x/sys/unix"
 )
 
+// _statusSignals are the signals asking a running timer for its status.
+var _statusSignals = []os.Signal{syscall.SIGUSR1}
+
+// _suspendSignals are the signals suspending the process, like Ctrl-Z.
+var _suspendSignals = []os.Signal{syscall.SIGTSTP}
+
+// _soundPlayers is the list of commands probed in order to find an
+// available audio player.
+var _soundPlayers = []string{
+	"ffplay -nodisp -autoexit -loglevel quiet -volume {volume} {file}",
+	"mpv --no-video --really-quiet --volume={volume} {file}",
+	"paplay {file}",
+	"aplay -q {file}",
+	"audacious --headless --quit-after-play {file}",
+}
+
+// _thenCommands are the commands of the actions of -then, probed in order
+// to find an available one.
+var _thenCommands = map[string][]string{
+	"lock":     {"loginctl lock-session", "xdg-screensaver lock"},
+	"suspend":  {"systemctl suspend", "loginctl suspend"},
+	"shutdown": {"systemctl poweroff", "shutdown -h now"},
+	"dim":      {"brightnessctl -q -s set {level}%", "ddcutil setvcp 10 {level}"},
+	// brightnessctl restores the brightness saved when dimming, ddcutil
+	// sets the full brightness
+	"restore": {"brightnessctl -q -r", "ddcutil setvcp 10 100"},
+}
+
+// _wslThenCommands are the commands of the actions of -then inside WSL,
+// acting on the Windows side.
+var _wslThenCommands = map[string][]string{
+	"lock":     {"rundll32.exe user32.dll,LockWorkStation"},
+	"suspend":  {"rundll32.exe powrprof.dll,SetSuspendState 0,1,0"},
+	"shutdown": {"shutdown.exe /s /t 0"},
+	"dim":      {`powershell.exe -NoProfile -Command "(Get-WmiObject -Namespace root/WMI -Class WmiMonitorBrightnessMethods).WmiSetBrightness(1, {level})"`},
+	"restore":  {`powershell.exe -NoProfile -Command "(Get-WmiObject -Namespace root/WMI -Class WmiMonitorBrightnessMethods).WmiSetBrightness(1, 100)"`},
+}
+
+// _wslSoundPlayer is the player probed last inside WSL, playing the sound
+// on the Windows side.
+const _wslSoundPlayer = `powershell.exe -NoProfile -Command "(New-Object Media.SoundPlayer '{winfile}').PlaySync()"`
+
+// _wslToast is the PowerShell script showing a Windows toast notification,
+// the title and the message are filled in as single quoted strings. Toasts
+// are shown on behalf of PowerShell as it is a registered application.
+const _wslToast = `$ErrorActionPreference = 'Stop'
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $xml.GetElementsByTagName('text')
+$text.Item(0).AppendChild($xml.CreateTextNode(%s)) > $null
+$text.Item(1).AppendChild($xml.CreateTextNode(%s)) > $null
+$app = '{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe'
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($app).Show([Windows.UI.Notifications.ToastNotification]::new($xml))`
+
+// _isWSL is true when running inside the Windows Subsystem for Linux.
+var _isWSL = detectWSL()
+
+func init() {
+	if _isWSL {
+		_soundPlayers = append(_soundPlayers, _wslSoundPlayer)
+		_thenCommands = _wslThenCommands
+	}
+}
+
+// detectWSL reports whether the process runs inside WSL, whose kernel
+// release names Microsoft.
+func detectWSL() bool {
+	if os.Getenv("WSL_DISTRO_NAME") != "" {
+		return true
+	}
+	release, err := ioutil.ReadFile("/proc/sys/kernel/osrelease")
+	return err == nil && strings.Contains(strings.ToLower(string(release)), "microsoft")
+}
+
+// getConfigDir returns the directory storing the configuration.
+func getConfigDir() string {
+	return filepath.Join(os.Getenv("HOME"), ".config", "timer")
+}
+
 // getSoundsDir returns the directory storing added sounds.
 func getSoundsDir() string {
 	return filepath.Join(os.Getenv("HOME"), ".config", "timer", "sounds")
 }
+
+// migrateLegacyDirs moves files from the locations used by earlier versions.
+// The locations have not changed on Linux.
+func migrateLegacyDirs() {}
+
+// rawInput disables line buffering and echo on the terminal fd so that a
+// single keypress can be read. The returned function restores the terminal.
+func rawInput(fd uintptr) (func(), error) {
+	termios, err := unix.IoctlGetTermios(int(fd), unix.TCGETS)
+	if err != nil {
+		return nil, err
+	}
+
+	old := *termios
+	termios.Lflag &^= unix.ECHO | unix.ICANON
+	termios.Cc[unix.VMIN] = 1
+	termios.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(int(fd), unix.TCSETS, termios); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		unix.IoctlSetTermios(int(fd), unix.TCSETS, &old)
+	}, nil
+}
+
+// enableVirtualTerminal reports whether ANSI escape sequences are handled,
+// which terminals always do.
+func enableVirtualTerminal() bool {
+	return true
+}
+
+// systemLocale returns the locale of the system when none is set in the
+// environment, which on Unix means the C locale.
+func systemLocale() string {
+	return ""
+}
+
+// setProcessGroup makes the command run in its own process group so that
+// the process and its children can be killed together.
+func setProcessGroup(ex *exec.Cmd) {
+	ex.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// detachProcess makes the command run detached from the terminal, so that it
+// keeps running after the terminal is closed.
+func detachProcess(ex *exec.Cmd) {
+	ex.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// killProcessGroup kills the process group led by the process p.
+func killProcessGroup(p *os.Process) error {
+	return syscall.Kill(-p.Pid, syscall.SIGKILL)
+}
+
+// stopProcess stops the process like the default action of SIGTSTP would,
+// it returns once the process is continued.
+func stopProcess() error {
+	return syscall.Kill(os.Getpid(), syscall.SIGSTOP)
+}
+
+// isExecutable reports whether the file can be run, by its permissions.
+func isExecutable(info os.FileInfo) bool {
+	return info.Mode()&0111 != 0
+}
+
+// showNotification shows a desktop notification. Inside WSL it is shown as
+// a Windows toast, as there is usually no notification daemon.
+func showNotification(title, message string) error {
+	if _isWSL {
+		if _, err := exec.LookPath("powershell.exe"); err == nil {
+			script := fmt.Sprintf(_wslToast, powershellQuote(title), powershellQuote(message))
+			return exec.Command("powershell.exe", "-NoProfile", "-Command", script).Run()
+		}
+	}
+	return beeep.Notify(title, message, "")
+}
+
+// windowsPath returns the location of the file as a Windows path. Inside WSL
+// it is translated with wslpath, elsewhere it is returned unchanged.
+func windowsPath(file string) string {
+	if !_isWSL {
+		return file
+	}
+	out, err := exec.Command("wslpath", "-w", file).Output()
+	if err != nil {
+		return file
+	}
+	return strings.TrimSpace(string(out))
+}
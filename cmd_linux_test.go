@@ -3,8 +3,11 @@
 package main
 
 import (
+	"context"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestConfigPath(t *testing.T) {
@@ -13,3 +16,236 @@ func TestConfigPath(t *testing.T) {
 		t.Errorf("want %s got %s", want, got)
 	}
 }
+
+func TestPlugins(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := getPluginsDir()
+	if err := os.MkdirAll(dir, 0776); err != nil {
+		t.Fatal(err)
+	}
+	out := dir + "/events.jsonl"
+	os.WriteFile(dir+"/record", []byte("#!/bin/sh\ncat >> "+out+"\necho \"$TIMER_EVENT $TIMER_EVENT_LABEL\" >> "+out+"\n"), 0755)
+	os.WriteFile(dir+"/fail", []byte("#!/bin/sh\necho broken >&2\nexit 3\n"), 0755)
+	os.WriteFile(dir+"/README", []byte("not a plugin"), 0644)
+
+	files, err := plugins()
+	if err != nil || len(files) != 2 || files[0] != dir+"/fail" || files[1] != dir+"/record" {
+		t.Fatalf("plugins() = %v, %v", files, err)
+	}
+	if err := runPlugin(files[0], []byte("{}"), nil); err == nil || err.Error() != "exit status 3: broken" {
+		t.Errorf("runPlugin(fail) = %v", err)
+	}
+	e := pluginEvent{Event: "start", Label: "Tea"}
+	if err := runPlugin(files[1], []byte(`{"event":"start"}`), e.env()); err != nil {
+		t.Fatalf("runPlugin(record) failed: %v", err)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "{\"event\":\"start\"}\nstart Tea\n" {
+		t.Errorf("plugin got %q", data)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	day := time.Date(2024, 3, 1, 9, 0, 0, 0, time.Local)
+	if err := os.MkdirAll(getConfigDir(), 0776); err != nil {
+		t.Fatal(err)
+	}
+	legacy := `{"kind":"pomodoro","start":"` + day.Format(time.RFC3339) + `","duration":1500}` + "\n"
+	if err := os.WriteFile(getLegacyHistoryFile(), []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []historyEntry{
+		{Kind: _historyTimer, Label: "Tea", Start: day.Add(2 * time.Hour), Duration: 240},
+		{Kind: _historyTimer, Label: "Tea", Start: day.AddDate(0, 0, 1), Duration: 240},
+		{Kind: _historyTimer, Start: day.Add(time.Hour), Duration: 60, Status: _statusCancelled, Elapsed: 30},
+	} {
+		if err := appendHistory(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(getLegacyHistoryFile() + ".bak"); err != nil {
+		t.Errorf("legacy history not kept: %v", err)
+	}
+
+	tests := []struct {
+		q    historyQuery
+		want []time.Time
+	}{
+		{historyQuery{}, []time.Time{day, day.Add(time.Hour), day.Add(2 * time.Hour), day.AddDate(0, 0, 1)}},
+		{historyQuery{from: day.Add(time.Minute), to: day.AddDate(0, 0, 1)}, []time.Time{day.Add(time.Hour), day.Add(2 * time.Hour)}},
+		{historyQuery{label: "Tea"}, []time.Time{day.Add(2 * time.Hour), day.AddDate(0, 0, 1)}},
+		{historyQuery{label: "Tea", to: day.AddDate(0, 0, 1)}, []time.Time{day.Add(2 * time.Hour)}},
+		{historyQuery{kind: _historyPomodoro}, []time.Time{day}},
+		{historyQuery{label: "Coffee"}, nil},
+	}
+	for _, test := range tests {
+		entries, err := queryHistory(test.q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []time.Time
+		for _, e := range entries {
+			got = append(got, e.Start)
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("%+v: want %v got %v", test.q, test.want, got)
+			continue
+		}
+		for i := range got {
+			if !got[i].Equal(test.want[i]) {
+				t.Errorf("%+v: want %v got %v", test.q, test.want, got)
+				break
+			}
+		}
+	}
+}
+
+func TestAnnotateTask(t *testing.T) {
+	dir := t.TempDir()
+	defer func(c string) { _taskCommand = c }(_taskCommand)
+	_taskCommand = dir + "/task"
+	script := "#!/bin/sh\necho \"$@\" >> " + dir + "/args\n[ \"$3\" = _get ] && echo PT10M\nexit 0\n"
+	if err := os.WriteFile(_taskCommand, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := &Cmd{config: &config{taskUDA: "timespent"}}
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.Local)
+	if err := cmd.annotateTask("12", start, start.Add(25*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(dir + "/args")
+	want := "rc.confirmation=off rc.verbose=nothing 12 annotate Timed 25m0s from 09:00 to 09:25\n" +
+		"rc.confirmation=off rc.verbose=nothing _get 12.timespent\n" +
+		"rc.confirmation=off rc.verbose=nothing 12 modify timespent:PT35M\n"
+	if string(got) != want {
+		t.Errorf("want %q got %q", want, got)
+	}
+}
+
+func TestTrack(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cmd := &Cmd{config: &config{}}
+	if err := cmd.trackStop(nil); err != errNotTracking {
+		t.Errorf("stop without session: want %v got %v", errNotTracking, err)
+	}
+	if err := cmd.trackStart([]string{"writing"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.trackStart([]string{"reading"}); err != errTracking {
+		t.Errorf("second start: want %v got %v", errTracking, err)
+	}
+	if err := cmd.trackStop(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadTrack(); !os.IsNotExist(err) {
+		t.Errorf("session not stopped: %v", err)
+	}
+
+	entries, err := queryHistory(historyQuery{kind: _historyTrack})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Label != "writing" || entries[0].status() != _statusStopped {
+		t.Errorf("want one stopped session writing got %+v", entries)
+	}
+}
+
+func TestThen(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+	defer func(c map[string][]string) { _thenCommands = c }(_thenCommands)
+	_thenCommands = map[string][]string{"lock": {"missing-locker", "locker --now"}, "dim": {"locker {level}%"}}
+	script := "#!/bin/sh\necho \"$@\" > " + dir + "/args\n"
+	if err := os.WriteFile(dir+"/locker", []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := checkThen("reboot"); err != errUnknownAction {
+		t.Errorf("unknown action: want %v got %v", errUnknownAction, err)
+	}
+	cmd := &Cmd{config: &config{}}
+	if err := cmd.runAction("lock"); err != nil {
+		t.Fatal(err)
+	}
+	if args, _ := os.ReadFile(dir + "/args"); string(args) != "--now\n" {
+		t.Errorf("want the available locker run with --now got %q", args)
+	}
+	cmd.config.dimLevel = 25
+	if err := cmd.runAction("dim"); err != nil {
+		t.Fatal(err)
+	}
+	if args, _ := os.ReadFile(dir + "/args"); string(args) != "25%\n" {
+		t.Errorf("want the screen dimmed to 25%% got %q", args)
+	}
+	_thenCommands["lock"] = []string{"missing-locker"}
+	if err := cmd.runAction("lock"); err != errNoAction {
+		t.Errorf("no locker: want %v got %v", errNoAction, err)
+	}
+}
+
+func TestLibraryFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if got, want := getLibraryFile(getSoundsDir()), home+"/.config/timer/sounds.json"; got != want {
+		t.Errorf("default sounds directory: want %s got %s", want, got)
+	}
+	a, b := getLibraryFile(home+"/sync/a"), getLibraryFile(home+"/sync/b")
+	if a == b || filepath.Dir(a) != getConfigDir() || filepath.Dir(b) != getConfigDir() {
+		t.Errorf("moved sounds directories: want distinct files in %s got %s and %s", getConfigDir(), a, b)
+	}
+	if got := getLibraryFile(home + "/sync/a/"); got != a {
+		t.Errorf("trailing slash: want %s got %s", a, got)
+	}
+}
+
+func TestExpiredReport(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cmd := &Cmd{ctx: context.Background(), config: &config{}, label: "writing"}
+	start := time.Now()
+	if err := cmd.countdownBetween(start, start.Add(100*time.Millisecond), 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	cmd.hooks.Wait()
+
+	from, to, _ := reportPeriod("day", start)
+	entries, err := queryHistory(historyQuery{from: from, to: to})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := buildReport("day", from, to, entries)
+	if len(r.Labels) != 1 || r.Labels[0].Label != "writing" || r.Labels[0].Minutes <= 0 {
+		t.Errorf("want the expired timer in the report got %+v", r.Labels)
+	}
+}
+
+func TestExpiredHistory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cmd := &Cmd{ctx: context.Background(), config: &config{}}
+	start := time.Now()
+	for _, label := range []string{"writing", ""} {
+		cmd.label = label
+		if err := cmd.countdownBetween(time.Now(), time.Now().Add(50*time.Millisecond), 50*time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A command recording its own history, like pomodoro, is not recorded twice
+	cmd.label, cmd.ownHistory = "writing", true
+	if err := cmd.countdownBetween(time.Now(), time.Now().Add(50*time.Millisecond), 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := queryHistory(historyQuery{from: start, label: "writing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Kind != _historyTimer || entries[0].status() != _statusExpired {
+		t.Errorf("want one expired timer writing got %+v", entries)
+	}
+	if entries, _ := queryHistory(historyQuery{from: start}); len(entries) != 2 {
+		t.Errorf("want the two expired timers got %+v", entries)
+	}
+	if entries, _ := queryHistory(historyQuery{to: start}); len(entries) != 0 {
+		t.Errorf("want no timer before the first one got %+v", entries)
+	}
+}
@@ -1,9 +1,171 @@
 package main
 
 import (
+	"flag"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestTimed(t *testing.T) {
 
 }
+
+func TestAdjustEnd(t *testing.T) {
+	cmd := &Cmd{}
+	cmd.args.step = time.Minute
+	start := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	end := start.Add(5 * time.Minute)
+
+	tests := []struct {
+		keys string
+		want time.Duration
+		ok   bool
+	}{
+		{"+", 6 * time.Minute, true},
+		{"=", 6 * time.Minute, true},
+		{"++-", 6 * time.Minute, true},
+		{"-", 4 * time.Minute, true},
+		{"------", 0, true},
+		{"\x1b[A", 5 * time.Minute, false},
+		{"x", 5 * time.Minute, false},
+	}
+	for _, tt := range tests {
+		got, ok := cmd.adjustEnd(tt.keys, start, end)
+		if got.Sub(start) != tt.want || ok != tt.ok {
+			t.Errorf("%q: want %v, %v got %v, %v", tt.keys, tt.want, tt.ok, got.Sub(start), ok)
+		}
+	}
+}
+
+func TestListSoundsFormat(t *testing.T) {
+	cmd := &Cmd{}
+	cmd.args.format = "csv"
+	if err := cmd.listSounds(); err != errUnknownFormat {
+		t.Errorf("-format csv: want %v got %v", errUnknownFormat, err)
+	}
+}
+
+func TestPlayerVolume(t *testing.T) {
+	t.Setenv(_timerSoundCommand, "paplay {file}")
+	cmd := &Cmd{meta: map[string]soundMeta{
+		"Bell":  {Command: "mpv --volume={volume} {file}"},
+		"Chime": {Command: "play -v VOLUME FILE"},
+	}}
+	for sound, want := range map[string]bool{"Bell": true, "Chime": true, "Alien": false} {
+		if got := cmd.playerVolume(sound); got != want {
+			t.Errorf("sound %s: want %v got %v", sound, want, got)
+		}
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	defer func(fs *flag.FlagSet) { flag.CommandLine = fs }(flag.CommandLine)
+
+	tests := []struct {
+		args       []string
+		positional []string
+		time       string
+		notify     bool
+	}{
+		{[]string{"25m", "Bell"}, []string{"25m", "Bell"}, "", false},
+		{[]string{"-n", "25m", "Bell"}, []string{"25m", "Bell"}, "", true},
+		{[]string{"25m", "-n", "Bell"}, []string{"25m", "Bell"}, "", true},
+		{[]string{"Bell", "-t", "25m", "-n"}, []string{"Bell"}, "25m", true},
+		{[]string{"-t=25m", "Bell", "-n=false"}, []string{"Bell"}, "25m", false},
+		{[]string{"--", "-n", "Bell"}, []string{"-n", "Bell"}, "", false},
+		{[]string{"25m", "--", "-n"}, []string{"25m", "-n"}, "", false},
+		{[]string{"-n", "--", "25m", "-t", "1m"}, []string{"25m", "-t", "1m"}, "", true},
+		// -- as the value of a flag is no terminator
+		{[]string{"-label", "--", "25m", "-n"}, []string{"25m"}, "", true},
+		{[]string{"history", "-from", "monday"}, []string{"history"}, "", false},
+	}
+	for _, tt := range tests {
+		flag.CommandLine = flag.NewFlagSet("timer", flag.ContinueOnError)
+		var label, from, tm string
+		var notify bool
+		flag.StringVar(&tm, "t", "", "")
+		flag.BoolVar(&notify, "n", false, "")
+		flag.StringVar(&label, "label", "", "")
+		flag.StringVar(&from, "from", "", "")
+
+		positional := parseFlags(tt.args)
+		if !reflect.DeepEqual(positional, tt.positional) || tm != tt.time || notify != tt.notify {
+			t.Errorf("%q: want %q, -t %q, -n %v got %q, -t %q, -n %v",
+				tt.args, tt.positional, tt.time, tt.notify, positional, tm, notify)
+		}
+	}
+}
+
+func TestPositionalArgs(t *testing.T) {
+	tests := []struct {
+		args        []string
+		time, until string
+		sound       string
+		rest        []string
+	}{
+		{[]string{"25m", "Bell"}, "", "", "", nil},
+		{[]string{"25m"}, "", "", "", nil},
+		{[]string{"Bell"}, "", "", "", []string{"Bell"}},
+		{[]string{"25m", "Bell", "extra"}, "", "", "", []string{"extra"}},
+		{[]string{"Bell"}, "", "17:00", "", nil},
+		{[]string{"25m"}, "10m", "", "", []string{"25m"}},
+		{[]string{"25m", "Bell"}, "", "", "Chime", []string{"Bell"}},
+	}
+	for _, tt := range tests {
+		cmd := &Cmd{config: &config{defaultUnit: time.Minute}}
+		cmd.args.time, cmd.args.until, cmd.args.sound = tt.time, tt.until, tt.sound
+		rest := cmd.positionalArgs(tt.args)
+		if len(rest) != len(tt.rest) || len(rest) > 0 && !reflect.DeepEqual(rest, tt.rest) {
+			t.Errorf("%q: want rest %q got %q", tt.args, tt.rest, rest)
+		}
+	}
+
+	cmd := &Cmd{config: &config{defaultUnit: time.Minute}}
+	cmd.positionalArgs([]string{"25", "Bell"})
+	if cmd.args.time != "25" || cmd.args.sound != "Bell" {
+		t.Errorf("25 Bell: want -t 25 -s Bell got -t %q -s %q", cmd.args.time, cmd.args.sound)
+	}
+	cmd = &Cmd{config: &config{defaultUnit: time.Minute}}
+	cmd.args.until = "17:00"
+	cmd.positionalArgs([]string{"Bell"})
+	if cmd.args.time != "" || cmd.args.sound != "Bell" {
+		t.Errorf("-until 17:00 Bell: want -s Bell got -t %q -s %q", cmd.args.time, cmd.args.sound)
+	}
+}
+
+func TestSubcommand(t *testing.T) {
+	called := ""
+	command := func(name string) func([]string) error {
+		return func([]string) error { called = name; return nil }
+	}
+	cmd := &Cmd{commands: map[string]func([]string) error{
+		"track":       command("track"),
+		"track start": command("track start"),
+		"history":     command("history"),
+	}}
+
+	tests := []struct {
+		args    []string
+		command string
+		rest    []string
+	}{
+		{[]string{"track", "start", "writing"}, "track start", []string{"writing"}},
+		{[]string{"track"}, "track", []string{}},
+		{[]string{"track", "-format", "json"}, "track", []string{"-format", "json"}},
+		{[]string{"history", "-from", "monday"}, "history", []string{"-from", "monday"}},
+		{[]string{"25m", "history"}, "", []string{"25m", "history"}},
+		{[]string{"-t", "25m"}, "", []string{"-t", "25m"}},
+		{nil, "", nil},
+	}
+	for _, tt := range tests {
+		called = ""
+		f, rest := cmd.subcommand(tt.args)
+		if f != nil {
+			f(rest)
+		}
+		if called != tt.command || !reflect.DeepEqual(rest, tt.rest) {
+			t.Errorf("%q: want %q, %q got %q, %q", tt.args, tt.command, tt.rest, called, rest)
+		}
+	}
+}
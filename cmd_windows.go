@@ -1,11 +1,202 @@
 package main
 
 import (
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/gen2brain/beeep"
+	"golang.org/x/sys/windows"
 )
 
-// getSoundsDir returns the directory storing added sounds.
+// _statusSignals are the signals asking a running timer for its status.
+// Windows has no signals to send to a running process.
+var _statusSignals = []os.Signal{}
+
+// _suspendSignals are the signals suspending the process, like Ctrl-Z.
+var _suspendSignals = []os.Signal{}
+
+// _soundPlayers is the list of commands probed in order to find an
+// available audio player.
+var _soundPlayers = []string{
+	"ffplay -nodisp -autoexit -loglevel quiet -volume {volume} {file}",
+	"mpv --no-video --really-quiet --volume={volume} {file}",
+	`powershell -NoProfile -Command "(New-Object Media.SoundPlayer '{file}').PlaySync()"`,
+}
+
+// _thenCommands are the commands of the actions of -then, probed in order
+// to find an available one.
+var _thenCommands = map[string][]string{
+	"lock":     {"rundll32.exe user32.dll,LockWorkStation"},
+	"suspend":  {"rundll32.exe powrprof.dll,SetSuspendState 0,1,0"},
+	"shutdown": {"shutdown.exe /s /t 0"},
+	// the brightness of laptop displays, restored to the full brightness
+	"dim":     {`powershell.exe -NoProfile -Command "(Get-WmiObject -Namespace root/WMI -Class WmiMonitorBrightnessMethods).WmiSetBrightness(1, {level})"`},
+	"restore": {`powershell.exe -NoProfile -Command "(Get-WmiObject -Namespace root/WMI -Class WmiMonitorBrightnessMethods).WmiSetBrightness(1, 100)"`},
+}
+
+// getConfigDir returns the directory storing the configuration, in the
+// roaming application data directory %APPDATA%.
+func getConfigDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return legacyConfigDir()
+	}
+	return filepath.Join(dir, "timer")
+}
+
+// getSoundsDir returns the directory storing added sounds, in the local
+// application data directory %LOCALAPPDATA% so that the sounds are not
+// copied around with a roaming profile.
 func getSoundsDir() string {
-	return filepath.Join(os.Getenv("HOME"), "AppData", "timer", "sounds")
+	dir := os.Getenv("LOCALAPPDATA")
+	if dir == "" {
+		return filepath.Join(getConfigDir(), "sounds")
+	}
+	return filepath.Join(dir, "timer", "sounds")
+}
+
+// legacyConfigDir returns the directory which stored the configuration and
+// the sounds in earlier versions.
+func legacyConfigDir() string {
+	return filepath.Join(os.Getenv("HOME"), "AppData", "timer")
+}
+
+// migrateLegacyDirs moves the configuration and the sounds from the
+// directory used by earlier versions to their current locations. Files are
+// only moved if they do not exist in the current location yet.
+func migrateLegacyDirs() {
+	if os.Getenv("HOME") == "" {
+		return
+	}
+	old := legacyConfigDir()
+	if _, err := os.Stat(old); err != nil {
+		return
+	}
+
+	moves := []struct{ from, to string }{
+		{filepath.Join(old, "config.toml"), getConfigFile()},
+		{filepath.Join(old, "sounds"), getSoundsDir()},
+		{filepath.Join(old, "sounds.json"), getLibraryFile(getSoundsDir())},
+	}
+	for _, m := range moves {
+		if m.from == m.to {
+			continue
+		}
+		if _, err := os.Stat(m.from); err != nil {
+			continue
+		}
+		if _, err := os.Stat(m.to); err == nil {
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(m.to), 0776); err != nil {
+			fmt.Println("Error creating directory:", err)
+			continue
+		}
+		if err := os.Rename(m.from, m.to); err != nil {
+			fmt.Printf("Error moving %s to %s: %v\n", m.from, m.to, err)
+		}
+	}
+
+	// Only removed once everything has been moved out
+	os.Remove(old)
+}
+
+// rawInput disables line buffering and echo on the console fd so that a
+// single keypress can be read. The returned function restores the console.
+func rawInput(fd uintptr) (func(), error) {
+	h := windows.Handle(fd)
+
+	var mode uint32
+	if err := windows.GetConsoleMode(h, &mode); err != nil {
+		return nil, err
+	}
+
+	raw := mode &^ (windows.ENABLE_ECHO_INPUT | windows.ENABLE_LINE_INPUT)
+	if err := windows.SetConsoleMode(h, raw); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		windows.SetConsoleMode(h, mode)
+	}, nil
+}
+
+// enableVirtualTerminal turns on the processing of ANSI escape sequences on
+// the console. It reports false on consoles which do not support it, like
+// those of Windows before Windows 10.
+func enableVirtualTerminal() bool {
+	h := windows.Handle(os.Stdout.Fd())
+
+	var mode uint32
+	if err := windows.GetConsoleMode(h, &mode); err != nil {
+		return false
+	}
+	if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+		return true
+	}
+	return windows.SetConsoleMode(h, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING) == nil
+}
+
+// systemLocale returns the name of the locale of the user, like de-DE.
+func systemLocale() string {
+	proc := windows.NewLazySystemDLL("kernel32.dll").NewProc("GetUserDefaultLocaleName")
+	if proc.Find() != nil {
+		return ""
+	}
+
+	// LOCALE_NAME_MAX_LENGTH
+	buf := make([]uint16, 85)
+	if n, _, _ := proc.Call(uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf))); n == 0 {
+		return ""
+	}
+	return windows.UTF16ToString(buf)
+}
+
+// setProcessGroup makes the command run in its own process group so that
+// it does not receive the console interrupt of the timer.
+func setProcessGroup(ex *exec.Cmd) {
+	ex.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// detachProcess makes the command run detached from the terminal, so that it
+// keeps running after the terminal is closed.
+func detachProcess(ex *exec.Cmd) {
+	ex.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | windows.DETACHED_PROCESS}
+}
+
+// killProcessGroup kills the process p.
+func killProcessGroup(p *os.Process) error {
+	return p.Kill()
+}
+
+// stopProcess stops the process like the default action of SIGTSTP would.
+// There is no such signal on Windows.
+func stopProcess() error {
+	return nil
+}
+
+// isExecutable reports whether the file can be run, by its extension.
+func isExecutable(info os.FileInfo) bool {
+	switch strings.ToLower(filepath.Ext(info.Name())) {
+	case ".exe", ".bat", ".cmd":
+		return true
+	}
+	return false
+}
+
+// showNotification shows a desktop notification.
+func showNotification(title, message string) error {
+	return beeep.Notify(title, message, "")
+}
+
+// windowsPath returns the location of the file as a Windows path, which it
+// already is.
+func windowsPath(file string) string {
+	return file
 }
@@ -8,7 +8,7 @@ import (
 )
 
 func TestConfigPath(t *testing.T) {
-	want, got := os.Getenv("HOME")+"AppData\\timer\\sounds", getSoundsDir()
+	want, got := os.Getenv("LOCALAPPDATA")+"\\timer\\sounds", getSoundsDir()
 	if want != got {
 		t.Errorf("want %s got %s", want, got)
 	}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"time"
+)
+
+const (
+	// remaining times under which the remaining time turns yellow, and red
+	// flashing, unless configured
+	_colorWarn  = time.Minute
+	_colorAlert = 10 * time.Second
+)
+
+// SGR sequences of the colors of the remaining time
+const (
+	_colorGreen  = "\x1b[32m"
+	_colorYellow = "\x1b[33m"
+	_colorRed    = "\x1b[1;31m"
+	// red on the color of the text, alternating with red to flash
+	_colorRedInverse = "\x1b[1;7;31m"
+	_colorReset      = "\x1b[0m"
+)
+
+// colorThresholds returns the remaining times under which the remaining
+// time turns yellow and flashes red, given with -color-warn and
+// -color-alert or in the config file.
+func (cmd *Cmd) colorThresholds() (time.Duration, time.Duration) {
+	warn, alert := _colorWarn, _colorAlert
+	if cmd.config.colorWarn > 0 {
+		warn = cmd.config.colorWarn
+	}
+	if cmd.config.colorAlert > 0 {
+		alert = cmd.config.colorAlert
+	}
+	if cmd.args.colorWarn > 0 {
+		warn = cmd.args.colorWarn
+	}
+	if cmd.args.colorAlert > 0 {
+		alert = cmd.args.colorAlert
+	}
+	return warn, alert
+}
+
+// colored reports whether the remaining time is colored: on terminals
+// handling ANSI escape sequences, unless -no-color is given or NO_COLOR is
+// set.
+func (cmd *Cmd) colored() bool {
+	return cmd.ansi && !cmd.args.noColor && os.Getenv("NO_COLOR") == ""
+}
+
+// paintRemaining returns the function coloring the text of the remaining
+// time: green, yellow under the warn threshold and red under the alert one,
+// flashing every other second.
+func (cmd *Cmd) paintRemaining(remaining time.Duration) func(string) string {
+	if !cmd.colored() {
+		return func(text string) string { return text }
+	}
+	warn, alert := cmd.colorThresholds()
+	color := remainingColor(remaining, warn, alert, time.Now().Unix()%2 == 1)
+	return func(text string) string { return color + text + _colorReset }
+}
+
+// remainingColor returns the color of the remaining time for the
+// thresholds, the inverse red when flash is true under the alert one.
+func remainingColor(remaining, warn, alert time.Duration, flash bool) string {
+	switch {
+	case remaining <= alert && flash:
+		return _colorRedInverse
+	case remaining <= alert:
+		return _colorRed
+	case remaining <= warn:
+		return _colorYellow
+	}
+	return _colorGreen
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRemainingColor(t *testing.T) {
+	tests := []struct {
+		remaining time.Duration
+		flash     bool
+		want      string
+	}{
+		{5 * time.Minute, false, _colorGreen},
+		{time.Minute, false, _colorYellow},
+		{30 * time.Second, true, _colorYellow},
+		{10 * time.Second, false, _colorRed},
+		{3 * time.Second, true, _colorRedInverse},
+	}
+	for _, test := range tests {
+		if got := remainingColor(test.remaining, time.Minute, 10*time.Second, test.flash); got != test.want {
+			t.Errorf("%v: want %q got %q", test.remaining, test.want, got)
+		}
+	}
+}
+
+func TestPaintRemaining(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	cmd := &Cmd{config: &config{colorWarn: 5 * time.Minute}, ansi: true}
+	cmd.args.colorAlert = time.Minute
+	if warn, alert := cmd.colorThresholds(); warn != 5*time.Minute || alert != time.Minute {
+		t.Errorf("want thresholds 5m and 1m got %v and %v", warn, alert)
+	}
+	if got := cmd.paintRemaining(2 * time.Minute)("02:00"); got != _colorYellow+"02:00"+_colorReset {
+		t.Errorf("want yellow got %q", got)
+	}
+
+	cmd.args.noColor = true
+	if got := cmd.paintRemaining(2 * time.Minute)("02:00"); got != "02:00" {
+		t.Errorf("-no-color: want no color got %q", got)
+	}
+	cmd.args.noColor = false
+	t.Setenv("NO_COLOR", "1")
+	if got := cmd.paintRemaining(2 * time.Minute)("02:00"); got != "02:00" {
+		t.Errorf("NO_COLOR: want no color got %q", got)
+	}
+}
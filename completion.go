@@ -0,0 +1,177 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+var (
+	errUnknownShell = errors.New("Unknown shell")
+)
+
+// _completionShells are the shells completion scripts are generated for.
+var _completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// _soundFlags are the flags whose value is the name of a sound. Their value
+// is completed with the names listed by timer -sounds.
+var _soundFlags = []string{"s", "sound", "d", "deletesound"}
+
+// completionData is the content of a completion script.
+type completionData struct {
+	// flags without the leading dash
+	Flags []string
+	// usage of each flag
+	Usage map[string]string
+	// first words of the commands and the words which may follow them
+	Commands    []string
+	Subcommands map[string][]string
+	SoundFlags  []string
+}
+
+// completion processes the command (completion SHELL).
+// Print the script completing the flags, commands, aliases, sound names and
+// profile names of timer in the given shell.
+func (cmd *Cmd) completion(args []string) error {
+	if len(args) != 1 {
+		fmt.Println("Expected the shell, one of", strings.Join(_completionShells, ", "))
+		return errInvalidArgs
+	}
+
+	tmpl, ok := _completionScripts[args[0]]
+	if !ok {
+		fmt.Println("Unknown shell, expected one of", strings.Join(_completionShells, ", "))
+		return errUnknownShell
+	}
+
+	data := completionData{
+		Usage:       make(map[string]string),
+		Subcommands: make(map[string][]string),
+		SoundFlags:  _soundFlags,
+	}
+	flag.VisitAll(func(f *flag.Flag) {
+		data.Flags = append(data.Flags, f.Name)
+		data.Usage[f.Name] = f.Usage
+	})
+
+	for command := range cmd.commands {
+		words := strings.Fields(command)
+		if _, ok := data.Subcommands[words[0]]; !ok {
+			data.Commands = append(data.Commands, words[0])
+			data.Subcommands[words[0]] = nil
+		}
+		if len(words) > 1 {
+			data.Subcommands[words[0]] = append(data.Subcommands[words[0]], words[1])
+		}
+	}
+	for alias := range cmd.config.aliases {
+		if _, ok := data.Subcommands[alias]; !ok {
+			data.Commands = append(data.Commands, alias)
+			data.Subcommands[alias] = nil
+		}
+	}
+	data.Subcommands["completion"] = append([]string(nil), _completionShells...)
+	sort.Strings(data.Commands)
+	for _, words := range data.Subcommands {
+		sort.Strings(words)
+	}
+
+	return template.Must(template.New(args[0]).Funcs(template.FuncMap{
+		"join":  strings.Join,
+		"quote": powershellQuote,
+	}).Parse(tmpl)).Execute(os.Stdout, data)
+}
+
+// powershellQuote returns s as a single quoted PowerShell string.
+func powershellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// _completionScripts are the templates of the completion script of each shell.
+var _completionScripts = map[string]string{
+	"bash": _bashCompletion,
+	// zsh runs the bash completion through its emulation of it
+	"zsh":        "autoload -U +X bashcompinit && bashcompinit\n" + _bashCompletion,
+	"fish":       _fishCompletion,
+	"powershell": _powershellCompletion,
+}
+
+const _bashCompletion = `# bash completion for timer, load with: source <(timer completion bash)
+_timer() {
+    local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}"
+    case "$prev" in
+        {{range $i, $f := .SoundFlags}}{{if $i}}|{{end}}-{{$f}}{{end}})
+            local IFS=$'\n'
+            COMPREPLY=($(compgen -W "$(timer -sounds 2>/dev/null)" -- "$cur"))
+            return
+            ;;
+        -profile)
+            COMPREPLY=($(compgen -W "$(timer profiles 2>/dev/null)" -- "$cur"))
+            return
+            ;;
+    esac
+
+    if [[ "$cur" == -* ]]; then
+        COMPREPLY=($(compgen -W "{{range $i, $f := .Flags}}{{if $i}} {{end}}-{{$f}}{{end}}" -- "$cur"))
+    elif [[ $COMP_CWORD -eq 1 ]]; then
+        COMPREPLY=($(compgen -W "{{join .Commands " "}}" -- "$cur"))
+    elif [[ $COMP_CWORD -eq 2 ]]; then
+        case "${COMP_WORDS[1]}" in
+{{- range $c, $words := .Subcommands}}{{if $words}}
+            {{$c}}) COMPREPLY=($(compgen -W "{{join $words " "}}" -- "$cur")) ;;{{end}}{{end}}
+            *) COMPREPLY=($(compgen -f -- "$cur")) ;;
+        esac
+    else
+        COMPREPLY=($(compgen -f -- "$cur"))
+    fi
+}
+complete -F _timer timer
+`
+
+const _fishCompletion = `# fish completion for timer, load with: timer completion fish | source
+{{range .Flags}}complete -c timer -o {{.}} -d {{quote (index $.Usage .)}}
+{{end}}
+{{- range .SoundFlags}}complete -c timer -o {{.}} -x -a "(timer -sounds 2>/dev/null)"
+{{end}}complete -c timer -o profile -x -a "(timer profiles 2>/dev/null)"
+{{range .Commands}}complete -c timer -n __fish_use_subcommand -a {{.}}
+{{end}}
+{{- range $c, $words := .Subcommands}}{{if $words}}complete -c timer -n "__fish_seen_subcommand_from {{$c}}" -a "{{join $words " "}}"
+{{end}}{{end}}`
+
+const _powershellCompletion = `# PowerShell completion for timer, load with:
+# timer completion powershell | Out-String | Invoke-Expression
+Register-ArgumentCompleter -Native -CommandName timer -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    $words = @($commandAst.CommandElements | ForEach-Object { $_.ToString() })
+    if ($wordToComplete) { $words = $words[0..($words.Count - 2)] }
+    $prev = $words[-1]
+
+    $subcommands = @{
+{{- range $c, $words := .Subcommands}}{{if $words}}
+        {{quote $c}} = @({{range $i, $w := $words}}{{if $i}}, {{end}}{{quote $w}}{{end}}){{end}}{{end}}
+    }
+
+    if (@({{range $i, $f := .SoundFlags}}{{if $i}}, {{end}}'-{{$f}}'{{end}}) -contains $prev) {
+        $candidates = @(timer -sounds 2>$null)
+    } elseif ($prev -eq '-profile') {
+        $candidates = @(timer profiles 2>$null)
+    } elseif ($wordToComplete -like '-*') {
+        $candidates = @({{range $i, $f := .Flags}}{{if $i}}, {{end}}'-{{$f}}'{{end}})
+    } elseif ($words.Count -eq 1) {
+        $candidates = @({{range $i, $c := .Commands}}{{if $i}}, {{end}}{{quote $c}}{{end}})
+    } elseif ($words.Count -eq 2 -and $subcommands.ContainsKey($words[1])) {
+        $candidates = $subcommands[$words[1]]
+    } else {
+        return
+    }
+
+    $candidates | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+`
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	errUnknownSetting = errors.New("unknown setting")
+)
+
+// configEntry is a key and value read from the config file.
+type configEntry struct {
+	section string
+	key     string
+	value   string
+	// line of the config file the entry was read from, 0 for an entry of
+	// the environment
+	line int
+}
+
+// where returns where the entry was read from, for errors.
+func (e configEntry) where() string {
+	if e.line == 0 {
+		return envName(e.key)
+	}
+	return fmt.Sprintf("config line %d", e.line)
+}
+
+// config is the user configuration read from the config file.
+type config struct {
+	// sound played when the sound named default is selected
+	defaultSound string
+	// directory storing added sounds instead of the default one
+	soundsDir string
+	// unit of a time value given as a bare number
+	defaultUnit time.Duration
+	// number of pomodoros to finish each day, 0 if there is no goal
+	pomodoroGoal int
+	// durations of the phases of pomodoro and how often the long break is
+	pomodoroWork           time.Duration
+	pomodoroShortBreak     time.Duration
+	pomodoroLongBreak      time.Duration
+	pomodoroLongBreakEvery int
+	// whether a timer is paused while the process is suspended with Ctrl-Z
+	pauseOnSuspend bool
+	// template of the body posted by -webhook
+	webhookTemplate string
+	// style of the progress line
+	progressStyle string
+	// remaining times under which the remaining time turns yellow and red
+	colorWarn  time.Duration
+	colorAlert time.Duration
+	// brightness in percent the screen is dimmed to by the dim action
+	dimLevel int
+	// templates of the progress line, of the message of an expired timer and
+	// of the notifications
+	progressTemplate string
+	expiredTemplate  string
+	notifyTemplate   string
+	// server, priority and tags of the messages published by -ntfy
+	ntfyServer   string
+	ntfyPriority string
+	ntfyTags     string
+	// Slack incoming webhook the end of every timer is posted to
+	slackWebhook string
+	// Prometheus Pushgateway the end of every timer is pushed to
+	pushgateway string
+	// OpenTelemetry collector the timers are sent to, and the headers of
+	// its requests
+	otlpEndpoint string
+	otlpHeaders  string
+	// Discord webhook and milestones of every timer, keyed by "", and of the
+	// timers of each preset, keyed by the name of the preset
+	discord map[string]discordConfig
+	// Telegram bot and chat the end of every timer is sent to, and how long
+	// an expired timer waits for a /snooze reply, 0 if it does not
+	telegramToken  string
+	telegramChat   string
+	telegramSnooze time.Duration
+	// SMTP server the emails of -email are sent through
+	smtp smtpConfig
+	// credentials of the push services of -notify-via
+	pushoverToken   string
+	pushoverUser    string
+	pushbulletToken string
+	// entries of each profile keyed by its name, from the [profile.NAME]
+	// sections
+	profiles map[string][]configEntry
+	// sound of the timers set by the profile, if any
+	profileSound string
+	// words each alias of the [alias] section stands for, by its name
+	aliases map[string]string
+	// duration UDA of the Taskwarrior tasks the time of -task is added to
+	taskUDA string
+	// time tracking services labelled timers are added to
+	toggl    togglConfig
+	clockify clockifyConfig
+	// org-mode file the expired timers are appended to, and how
+	orgFile   string
+	orgFormat string
+	// server the config file and the history are synced with
+	sync syncConfig
+}
+
+// getConfigFile returns the location of the config file.
+func getConfigFile() string {
+	return filepath.Join(getConfigDir(), "config.toml")
+}
+
+// loadConfig reads the config file. A missing config file is an empty
+// configuration.
+func loadConfig() (*config, error) {
+	cfg := &config{
+		defaultUnit:            time.Minute,
+		pomodoroWork:           _pomodoroWork,
+		pomodoroShortBreak:     _pomodoroShortBreak,
+		pomodoroLongBreak:      _pomodoroLongBreak,
+		pomodoroLongBreakEvery: _pomodoroLongBreakEvery,
+		pauseOnSuspend:         true,
+		discord:                make(map[string]discordConfig),
+		profiles:               make(map[string][]configEntry),
+		aliases:                make(map[string]string),
+	}
+
+	data, err := ioutil.ReadFile(getConfigFile())
+	if os.IsNotExist(err) {
+		return cfg, cfg.setEnv()
+	} else if err != nil {
+		return nil, err
+	}
+
+	entries, err := parseConfig(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if entries, err = migrateConfig(string(data), entries); err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if e.section == "alias" {
+			if _, err := splitWords(e.value); err != nil {
+				return nil, fmt.Errorf("%s: alias %s: %v", e.where(), e.key, err)
+			}
+			cfg.aliases[e.key] = e.value
+			continue
+		}
+		if name := strings.TrimPrefix(e.section, "profile."); name != e.section {
+			if err := checkProfileEntry(e); err != nil {
+				return nil, err
+			}
+			cfg.profiles[name] = append(cfg.profiles[name], e)
+			continue
+		}
+		if err := cfg.set(e); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.setEnv(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// set sets the setting of the entry, which is either of the top of the
+// config file or a Discord setting of the section of a preset.
+func (cfg *config) set(e configEntry) error {
+	var err error
+	_, preset := _presets[e.section]
+	if e.section != "" && !preset {
+		return fmt.Errorf("%s: unknown section [%s]", e.where(), e.section)
+	}
+	if e.key == "discord_webhook" || e.key == "discord_milestones" {
+		d := cfg.discord[e.section]
+		if e.key == "discord_webhook" {
+			d.webhook = e.value
+		} else if d.milestones, err = parseMilestones(e.value); err != nil {
+			return fmt.Errorf("%s: %v", e.where(), err)
+		}
+		cfg.discord[e.section] = d
+		return nil
+	}
+	if preset {
+		return fmt.Errorf("%s: %s cannot be set for the preset %s", e.where(), e.key, e.section)
+	}
+	switch e.key {
+	case "version":
+		// Checked by migrateConfig
+	case "default_sound":
+		cfg.defaultSound = e.value
+	case "sounds_dir":
+		cfg.soundsDir = e.value
+	case "default_unit":
+		if cfg.defaultUnit, err = parseTimeUnit(e.value); err != nil {
+			return fmt.Errorf("%s: %v", e.where(), err)
+		}
+	case "pomodoro_goal":
+		if cfg.pomodoroGoal, err = strconv.Atoi(e.value); err != nil || cfg.pomodoroGoal < 0 {
+			return fmt.Errorf("%s: pomodoro_goal must be a number", e.where())
+		}
+	case "pomodoro_work":
+		if cfg.pomodoroWork, err = parseConfigDuration(e); err != nil {
+			return err
+		}
+	case "pomodoro_short_break":
+		if cfg.pomodoroShortBreak, err = parseConfigDuration(e); err != nil {
+			return err
+		}
+	case "pomodoro_long_break":
+		if cfg.pomodoroLongBreak, err = parseConfigDuration(e); err != nil {
+			return err
+		}
+	case "pomodoro_long_break_every":
+		if cfg.pomodoroLongBreakEvery, err = strconv.Atoi(e.value); err != nil || cfg.pomodoroLongBreakEvery < 1 {
+			return fmt.Errorf("%s: pomodoro_long_break_every must be a positive number", e.where())
+		}
+	case "ntfy_server":
+		cfg.ntfyServer = e.value
+	case "ntfy_priority":
+		if err := checkPriority(e.value); err != nil {
+			return fmt.Errorf("%s: %v", e.where(), err)
+		}
+		cfg.ntfyPriority = e.value
+	case "ntfy_tags":
+		cfg.ntfyTags = e.value
+	case "telegram_token":
+		cfg.telegramToken = e.value
+	case "telegram_chat_id":
+		cfg.telegramChat = e.value
+	case "telegram_snooze":
+		if cfg.telegramSnooze, err = parseConfigDuration(e); err != nil {
+			return err
+		}
+	case "smtp_host":
+		cfg.smtp.host = e.value
+	case "smtp_port":
+		cfg.smtp.port = e.value
+	case "smtp_user":
+		cfg.smtp.user = e.value
+	case "smtp_password":
+		cfg.smtp.password = e.value
+	case "smtp_from":
+		cfg.smtp.from = e.value
+	case "pushover_token":
+		cfg.pushoverToken = e.value
+	case "pushover_user":
+		cfg.pushoverUser = e.value
+	case "pushbullet_token":
+		cfg.pushbulletToken = e.value
+	case "otlp_endpoint":
+		cfg.otlpEndpoint = e.value
+	case "otlp_headers":
+		cfg.otlpHeaders = e.value
+	case "pushgateway_url":
+		cfg.pushgateway = e.value
+	case "slack_webhook":
+		cfg.slackWebhook = e.value
+	case "color_warn":
+		if cfg.colorWarn, err = parseConfigDuration(e); err != nil {
+			return err
+		}
+	case "color_alert":
+		if cfg.colorAlert, err = parseConfigDuration(e); err != nil {
+			return err
+		}
+	case "dim_level":
+		if cfg.dimLevel, err = strconv.Atoi(e.value); err != nil || cfg.dimLevel < 1 || cfg.dimLevel > 100 {
+			return fmt.Errorf("%s: dim_level must be a number from 1 to 100", e.where())
+		}
+	case "progress_style":
+		cfg.progressStyle = e.value
+	case "progress_template":
+		cfg.progressTemplate = e.value
+	case "expired_template":
+		cfg.expiredTemplate = e.value
+	case "notify_template":
+		cfg.notifyTemplate = e.value
+	case "webhook_template":
+		cfg.webhookTemplate = e.value
+	case "task_uda":
+		cfg.taskUDA = e.value
+	case "toggl_token":
+		cfg.toggl.token = e.value
+	case "toggl_workspace":
+		if cfg.toggl.workspace, err = strconv.Atoi(e.value); err != nil {
+			return fmt.Errorf("%s: toggl_workspace must be the number of the workspace", e.where())
+		}
+	case "toggl_project":
+		if cfg.toggl.project, err = strconv.Atoi(e.value); err != nil {
+			return fmt.Errorf("%s: toggl_project must be the number of the project", e.where())
+		}
+	case "clockify_key":
+		cfg.clockify.key = e.value
+	case "clockify_workspace":
+		cfg.clockify.workspace = e.value
+	case "clockify_project":
+		cfg.clockify.project = e.value
+	case "sync_url":
+		cfg.sync.url = e.value
+	case "sync_user":
+		cfg.sync.user = e.value
+	case "sync_password":
+		cfg.sync.password = e.value
+	case "org_file":
+		cfg.orgFile = e.value
+	case "org_format":
+		if e.value != "clock" && e.value != "list" {
+			return fmt.Errorf("%s: %v", e.where(), errUnknownOrgFormat)
+		}
+		cfg.orgFormat = e.value
+	case "pause_on_suspend":
+		if cfg.pauseOnSuspend, err = strconv.ParseBool(e.value); err != nil {
+			return fmt.Errorf("%s: pause_on_suspend must be true or false", e.where())
+		}
+	default:
+		return fmt.Errorf("%s: %w %s", e.where(), errUnknownSetting, e.key)
+	}
+	return nil
+}
+
+// parseConfigDuration parses the value of the entry as a time value, where a
+// bare number counts minutes.
+func parseConfigDuration(e configEntry) (time.Duration, error) {
+	d, err := parseTime(e.value, time.Minute)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s: %s must be a time value like 25m", e.where(), e.key)
+	}
+	return d, nil
+}
+
+// parseConfig parses the content of the config file. The format is a small
+// subset of TOML: lines of key = value, [section] headers starting a new
+// section and # comments. A value is a double quoted string or a bare word.
+func parseConfig(data string) ([]configEntry, error) {
+	var (
+		entries []configEntry
+		section string
+	)
+
+	for i, line := range strings.Split(data, "\n") {
+		n := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			end := strings.Index(line, "]")
+			if end < 0 || strings.TrimSpace(stripComment(line[end+1:])) != "" {
+				return nil, fmt.Errorf("config line %d: invalid section header", n)
+			}
+			section = strings.TrimSpace(line[1:end])
+			continue
+		}
+
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			return nil, fmt.Errorf("config line %d: expected key = value", n)
+		}
+		key := strings.TrimSpace(line[:eq])
+		if key == "" {
+			return nil, fmt.Errorf("config line %d: missing key", n)
+		}
+
+		value, err := parseConfigValue(strings.TrimSpace(line[eq+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("config line %d: %v", n, err)
+		}
+		entries = append(entries, configEntry{section: section, key: key, value: value, line: n})
+	}
+
+	return entries, nil
+}
+
+// parseConfigValue returns the value of a key, which is either a double
+// quoted string or a bare word, followed by an optional comment.
+func parseConfigValue(s string) (string, error) {
+	if !strings.HasPrefix(s, `"`) {
+		return strings.TrimSpace(stripComment(s)), nil
+	}
+
+	// Find the closing quote, skipping escaped quotes
+	end := 1
+	for ; end < len(s) && s[end] != '"'; end++ {
+		if s[end] == '\\' {
+			end++
+		}
+	}
+	if end >= len(s) {
+		return "", fmt.Errorf("unterminated string")
+	}
+	if strings.TrimSpace(stripComment(s[end+1:])) != "" {
+		return "", fmt.Errorf("unexpected text after string")
+	}
+	return strconv.Unquote(s[:end+1])
+}
+
+// stripComment removes a trailing # comment.
+func stripComment(s string) string {
+	if i := strings.Index(s, "#"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseConfig(t *testing.T) {
+	data := `# timer config
+default_sound = "Rooster"   # wake up
+volume = 50
+
+[alias]
+tea = "-t 4m -s \"Green Tea\" -n"
+`
+	want := []configEntry{
+		{section: "", key: "default_sound", value: "Rooster", line: 2},
+		{section: "", key: "volume", value: "50", line: 3},
+		{section: "alias", key: "tea", value: `-t 4m -s "Green Tea" -n`, line: 6},
+	}
+
+	got, err := parseConfig(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %+v got %+v", want, got)
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	tests := []string{
+		"default_sound",
+		"= Rooster",
+		`default_sound = "Rooster`,
+		`default_sound = "Rooster" Alien`,
+		"[alias",
+	}
+
+	for _, data := range tests {
+		if _, err := parseConfig(data); err == nil {
+			t.Errorf("%q: expected error", data)
+		}
+	}
+}
+
+func TestConfigSetErrors(t *testing.T) {
+	tests := []configEntry{
+		{key: "defualt_sound", value: "Rooster", line: 1},
+		{key: "pomodoro_goal", value: "many", line: 2},
+		{key: "pause_on_suspend", value: "maybe", line: 3},
+		{key: "dim_level", value: "150", line: 4},
+		{section: "tabata", key: "default_sound", value: "Rooster", line: 5},
+		{section: "sounds", key: "default_sound", value: "Rooster", line: 7},
+	}
+
+	for _, e := range tests {
+		cfg := &config{discord: make(map[string]discordConfig)}
+		if err := cfg.set(e); err == nil {
+			t.Errorf("%+v: expected error", e)
+		}
+	}
+}
+
+func TestCheckProfileEntry(t *testing.T) {
+	tests := []struct {
+		key, value string
+		ok         bool
+	}{
+		{"sound", "Chime", true},
+		{"notify", "true", true},
+		{"notify", "often", false},
+		{"volume", "40", true},
+		{"volume", "140", false},
+		{"default_unit", "s", true},
+		{"default_unit", "days", false},
+		{"colour", "red", false},
+		{"version", "1", false},
+		{"sounds_dir", "/tmp/sounds", false},
+	}
+
+	for _, test := range tests {
+		err := checkProfileEntry(configEntry{section: "profile.work", key: test.key, value: test.value, line: 1})
+		if ok := err == nil; ok != test.ok {
+			t.Errorf("%s = %s: want ok %v got %v", test.key, test.value, test.ok, err)
+		}
+	}
+}
+
+func TestParseConfigDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"50m", 50 * time.Minute, true},
+		{"10", 10 * time.Minute, true},
+		{"1:30:00", 90 * time.Minute, true},
+		{"0", 0, false},
+		{"long", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, err := parseConfigDuration(configEntry{key: "pomodoro_work", value: tt.value, line: 1})
+		if got != tt.want || (err == nil) != tt.ok {
+			t.Errorf("%s: want %v, %v got %v, %v", tt.value, tt.want, tt.ok, got, err)
+		}
+	}
+}
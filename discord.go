@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// discordConfig is where and when the end of a timer is posted to Discord.
+type discordConfig struct {
+	// Discord webhook the messages are posted to
+	webhook string
+	// remaining times announced before the timer expires, longest first
+	milestones []time.Duration
+}
+
+// discord returns the Discord webhook and milestones of the running timer.
+// The settings of the config file are overridden by the section of the
+// running preset and by -discord-webhook and -discord-milestones.
+func (cmd *Cmd) discord() discordConfig {
+	d := cmd.config.discord[""]
+	if p, ok := cmd.config.discord[cmd.preset]; ok && cmd.preset != "" {
+		if p.webhook != "" {
+			d.webhook = p.webhook
+		}
+		if p.milestones != nil {
+			d.milestones = p.milestones
+		}
+	}
+	if cmd.args.discordWebhook != "" {
+		d.webhook = cmd.args.discordWebhook
+	}
+	if cmd.args.discordMilestones != "" {
+		// Checked when the flags are parsed
+		d.milestones, _ = parseMilestones(cmd.args.discordMilestones)
+	}
+	return d
+}
+
+// parseMilestones parses the comma separated list of remaining times to
+// announce, like 10m,5m,1m, sorted longest first.
+func parseMilestones(s string) ([]time.Duration, error) {
+	var milestones []time.Duration
+	for _, m := range strings.Split(s, ",") {
+		d, err := parseTime(strings.TrimSpace(m), time.Minute)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("Invalid milestone %s", m)
+		}
+		milestones = append(milestones, d)
+	}
+	sort.Slice(milestones, func(i, j int) bool { return milestones[i] > milestones[j] })
+	return milestones, nil
+}
+
+// sendMilestones posts the milestones reached with the remaining time to
+// Discord. The number of milestones already passed is given as sent and the
+// new number is returned, to be given for the next check. Milestones as long
+// as the timer itself are passed without being announced.
+func (cmd *Cmd) sendMilestones(remaining time.Duration, sent int, total time.Duration) int {
+	d := cmd.discord()
+	for ; sent < len(d.milestones) && remaining <= d.milestones[sent]; sent++ {
+		if d.webhook == "" || d.milestones[sent] >= total || remaining <= 0 {
+			continue
+		}
+		text := fmt.Sprintf(tr(_msgRemaining), d.milestones[sent])
+		if cmd.label != "" {
+			text = cmd.label + ": " + text
+		}
+		cmd.sendHook("discord", func() error { return sendDiscord(d.webhook, ":hourglass: "+text) })
+	}
+	return sent
+}
+
+// sendDiscord posts the message to the channel of the Discord webhook at the
+// URL.
+func sendDiscord(url, text string) error {
+	body, err := json.Marshal(struct {
+		Content string `json:"content"`
+	}{text})
+	if err != nil {
+		return err
+	}
+	return postHook(url, http.Header{"Content-Type": {"application/json"}}, body)
+}
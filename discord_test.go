@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseMilestones(t *testing.T) {
+	got, err := parseMilestones("1m, 10m,30s")
+	if err != nil {
+		t.Fatalf("parseMilestones failed: %v", err)
+	}
+	if want := []time.Duration{10 * time.Minute, time.Minute, 30 * time.Second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseMilestones = %v, want %v", got, want)
+	}
+	for _, s := range []string{"", "5m,", "soon", "0s"} {
+		if _, err := parseMilestones(s); err == nil {
+			t.Errorf("parseMilestones(%q) did not fail", s)
+		}
+	}
+}
+
+func TestDiscordConfig(t *testing.T) {
+	cmd := &Cmd{config: &config{discord: map[string]discordConfig{
+		"":       {webhook: "https://discord.example/all", milestones: []time.Duration{time.Minute}},
+		"tabata": {milestones: []time.Duration{10 * time.Second}},
+	}}}
+	if d := cmd.discord(); d.webhook != "https://discord.example/all" || len(d.milestones) != 1 || d.milestones[0] != time.Minute {
+		t.Errorf("discord() = %+v without a preset", d)
+	}
+	cmd.preset = "tabata"
+	if d := cmd.discord(); d.webhook != "https://discord.example/all" || len(d.milestones) != 1 || d.milestones[0] != 10*time.Second {
+		t.Errorf("discord() = %+v with the tabata preset", d)
+	}
+	cmd.args.discordWebhook, cmd.args.discordMilestones = "https://discord.example/mine", "2m"
+	if d := cmd.discord(); d.webhook != "https://discord.example/mine" || len(d.milestones) != 1 || d.milestones[0] != 2*time.Minute {
+		t.Errorf("discord() = %+v with flags", d)
+	}
+}
+
+func TestSendMilestones(t *testing.T) {
+	posted := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		posted <- string(data)
+	}))
+	defer server.Close()
+
+	cmd := &Cmd{config: &config{discord: map[string]discordConfig{
+		"": {webhook: server.URL, milestones: []time.Duration{10 * time.Minute, 5 * time.Minute, time.Minute}},
+	}}}
+	total := 5 * time.Minute
+	// The milestones as long as the timer are passed without a message
+	sent := cmd.sendMilestones(total, 0, total)
+	if sent = cmd.sendMilestones(2*time.Minute, sent, total); sent != 2 {
+		t.Errorf("sendMilestones at 2m = %d, want 2", sent)
+	}
+	if sent = cmd.sendMilestones(59*time.Second, sent, total); sent != 3 {
+		t.Errorf("sendMilestones at 59s = %d, want 3", sent)
+	}
+	cmd.hooks.Wait()
+	close(posted)
+
+	var messages []string
+	for m := range posted {
+		messages = append(messages, m)
+	}
+	want := `{"content":":hourglass: ` + fmt.Sprintf(tr(_msgRemaining), time.Minute) + `"}`
+	if len(messages) != 1 || messages[0] != want {
+		t.Errorf("posted %q, want a single message %s", messages, want)
+	}
+}
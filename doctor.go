@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+)
+
+var (
+	errChecksFailed = errors.New("Some checks failed")
+)
+
+// doctor processes the command (doctor).
+// Check that sounds can be played, notifications shown and the configuration
+// and sounds directories written, and print the resolved configuration.
+func (cmd *Cmd) doctor(args []string) error {
+	if len(args) != 0 {
+		fmt.Println("Unexpected arguments to doctor")
+		return errInvalidArgs
+	}
+
+	failed := false
+	check := func(name string, err error) {
+		if err != nil {
+			failed = true
+			fmt.Printf("FAIL  %s: %v\n", name, err)
+		} else {
+			fmt.Printf("ok    %s\n", name)
+		}
+	}
+	warn := func(name string, err error) {
+		if err != nil {
+			fmt.Printf("warn  %s: %v\n", name, err)
+		} else {
+			fmt.Printf("ok    %s\n", name)
+		}
+	}
+
+	fmt.Println("Configuration")
+	settings := [][2]string{
+		{"config file", describeFile(getConfigFile())},
+		{"sounds directory", cmd.soundsDir},
+		{"sound library", describeFile(getLibraryFile(cmd.soundsDir))},
+		{"sounds", fmt.Sprint(len(cmd.sounds))},
+		{"default_sound", describeValue(cmd.config.defaultSound)},
+		{"sounds_dir", describeValue(cmd.config.soundsDir)},
+	}
+	for _, name := range []string{_timerSoundCommand, _timerSoundsDir, _timerSoundIndex} {
+		settings = append(settings, [2]string{name, describeValue(os.Getenv(name))})
+	}
+	for _, s := range settings {
+		fmt.Printf("  %-18s %s\n", s[0], s[1])
+	}
+	fmt.Println()
+
+	fmt.Println("Checks")
+	command := soundCommand()
+	if command == "" {
+		check("sound player", errNoSoundPlayer)
+	} else {
+		check("sound player "+command, checkCommand(command))
+	}
+	warn("ffmpeg, used by -normalize and -fade-in", checkCommand("ffmpeg"))
+	warn("ffprobe, used to show the duration of sounds", checkCommand("ffprobe"))
+	check("config directory is writable", checkWritable(getConfigDir()))
+	check("sounds directory is writable", checkWritable(cmd.soundsDir))
+	check("test notification sent", showNotification("Timer", "This is a test notification"))
+
+	if failed {
+		return errChecksFailed
+	}
+	return nil
+}
+
+// describeFile returns the location of the file, noting if it is missing.
+func describeFile(file string) string {
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		return file + " (missing)"
+	}
+	return file
+}
+
+// describeValue returns the value of a setting, or that it is not set.
+func describeValue(v string) string {
+	if v == "" {
+		return "(not set)"
+	}
+	return v
+}
+
+// checkCommand checks that the program run by the command is installed.
+func checkCommand(command string) error {
+	words, err := splitWords(command)
+	if err != nil {
+		return err
+	}
+	if len(words) == 0 {
+		return errNoSoundPlayer
+	}
+	_, err = exec.LookPath(words[0])
+	return err
+}
+
+// checkWritable checks that a file can be created in the directory.
+func checkWritable(dir string) error {
+	f, err := ioutil.TempFile(dir, ".timer-doctor-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
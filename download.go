@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	errDownloadTooLarge = errors.New("Downloaded file is too large")
+	errNotAudio         = errors.New("Downloaded file is not an audio file")
+)
+
+const (
+	// largest sound file accepted for download, 50 MiB
+	_maxDownloadSize = 50 << 20
+	// time allowed for a download to complete
+	_downloadTimeout = 2 * time.Minute
+)
+
+// isURL reports whether the location is a http or https URL.
+func isURL(loc string) bool {
+	return strings.HasPrefix(loc, "http://") || strings.HasPrefix(loc, "https://")
+}
+
+// download streams the file at the URL into a temporary file in dir and
+// returns its location along with the name of the file taken from the URL
+// path. The caller renames the temporary file into place or removes it.
+// Only responses with an audio or generic binary content type no larger than
+// _maxDownloadSize are accepted.
+func download(ctx context.Context, loc, dir string) (string, string, error) {
+	u, err := url.Parse(loc)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := os.MkdirAll(dir, 0776); err != nil {
+		return "", "", err
+	}
+	tmp, err := ioutil.TempFile(dir, ".download-*")
+	if err != nil {
+		return "", "", err
+	}
+	err = fetchTo(ctx, loc, isAudioContentType, tmp)
+	if e := tmp.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", "", err
+	}
+	return tmp.Name(), path.Base(u.Path), nil
+}
+
+// fetch returns the content at the URL. The response is rejected if it is
+// larger than _maxDownloadSize or if accept, when not nil, returns false for
+// its content type.
+func fetch(ctx context.Context, loc string, accept func(contentType string) bool) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := fetchTo(ctx, loc, accept, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fetchTo writes the content at the URL to w, rejecting it like fetch. Part
+// of the content may have been written when an error is returned.
+func fetchTo(ctx context.Context, loc string, accept func(contentType string) bool, w io.Writer) error {
+	ctx, cancel := context.WithTimeout(ctx, _downloadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, loc, nil)
+	if err != nil {
+		return err
+	}
+
+	slog.Debug("downloading", "url", loc)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	slog.Debug("download response", "url", loc, "status", resp.Status,
+		"content_type", resp.Header.Get("Content-Type"), "size", resp.ContentLength)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Download failed: %s", resp.Status)
+	}
+	if resp.ContentLength > _maxDownloadSize {
+		return errDownloadTooLarge
+	}
+	if accept != nil && !accept(resp.Header.Get("Content-Type")) {
+		return errNotAudio
+	}
+
+	n, err := io.Copy(w, io.LimitReader(resp.Body, _maxDownloadSize+1))
+	if err != nil {
+		return err
+	}
+	if n > _maxDownloadSize {
+		return errDownloadTooLarge
+	}
+
+	return nil
+}
+
+// isAudioContentType reports whether the content type may hold an audio file.
+// Servers often send audio files as generic binary data, so that is accepted
+// as well.
+func isAudioContentType(contentType string) bool {
+	t := strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	return t == "" || strings.HasPrefix(t, "audio/") ||
+		t == "application/ogg" || t == "application/octet-stream"
+}
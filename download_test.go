@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDownload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sounds/Bell.ogg":
+			w.Header().Set("Content-Type", "audio/ogg")
+			w.Write([]byte("OggS"))
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html>"))
+		case "/large.wav":
+			// Without a Content-Length the size is only known while streaming
+			w.Header().Set("Content-Type", "audio/wav")
+			w.(http.Flusher).Flush()
+			io.Copy(w, io.LimitReader(zeros{}, _maxDownloadSize+1))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "sounds")
+	file, name, err := download(context.Background(), server.URL+"/sounds/Bell.ogg", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "Bell.ogg" || filepath.Dir(file) != dir {
+		t.Errorf("want Bell.ogg in %s got %s in %s", dir, name, file)
+	}
+	if data, err := os.ReadFile(file); err != nil || !bytes.Equal(data, []byte("OggS")) {
+		t.Errorf("content: want OggS got %q, %v", data, err)
+	}
+	os.Remove(file)
+
+	for path, want := range map[string]error{"/page.html": errNotAudio, "/large.wav": errDownloadTooLarge} {
+		if _, _, err := download(context.Background(), server.URL+path, dir); err != want {
+			t.Errorf("%s: want %v got %v", path, want, err)
+		}
+	}
+	if _, _, err := download(context.Background(), server.URL+"/missing.wav", dir); err == nil {
+		t.Error("/missing.wav: want an error")
+	}
+	// Failed downloads leave no temporary files behind
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("want no files left got %v", files)
+	}
+}
+
+// zeros reads an endless stream of zero bytes.
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
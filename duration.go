@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	errInvalidTime = errors.New("Time must be like 1h20m30s, 1:20:30, 20:30 or 25")
+	errInvalidUnit = errors.New("Unit must be one of s, m or h")
+)
+
+// _timeUnits are the units a bare number of a time value can be counted in.
+var _timeUnits = map[string]time.Duration{
+	"s": time.Second, "sec": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hours": time.Hour,
+}
+
+// parseTime parses a time value, which is a sum of parts separated by + like
+// 45m + 10m. A part is a duration like 1h20m30s, a clock time of the form
+// H:MM:SS or M:SS, or a bare number counting unit.
+func parseTime(s string, unit time.Duration) (time.Duration, error) {
+	var total time.Duration
+	for _, part := range strings.Split(s, "+") {
+		d, err := parseTimePart(strings.TrimSpace(part), unit)
+		if err != nil {
+			return 0, err
+		}
+		total += d
+	}
+	return total, nil
+}
+
+// parseTimePart parses a single part of a time value.
+func parseTimePart(s string, unit time.Duration) (time.Duration, error) {
+	if isBareNumber(s) {
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, errInvalidTime
+		}
+		return time.Duration(n * float64(unit)), nil
+	}
+	if !strings.Contains(s, ":") {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, errInvalidTime
+		}
+		return d, nil
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0, errInvalidTime
+	}
+
+	// The last part counts seconds, the ones before it minutes and hours
+	var d time.Duration
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || strings.HasPrefix(part, "+") {
+			return 0, errInvalidTime
+		}
+		if i > 0 && (n >= 60 || len(part) != 2) {
+			return 0, errInvalidTime
+		}
+		d = d*60 + time.Duration(n)
+	}
+	return d * time.Second, nil
+}
+
+// timeSum is a flag.Value adding the time values given with each use of the
+// flag up, so that -t 1h -t 30m is the same as -t "1h + 30m".
+type timeSum struct {
+	value *string
+}
+
+func (t timeSum) String() string {
+	if t.value == nil {
+		return ""
+	}
+	return *t.value
+}
+
+func (t timeSum) Set(s string) error {
+	if *t.value != "" {
+		*t.value += " + "
+	}
+	*t.value += s
+	return nil
+}
+
+// isBareNumber reports whether the time value is a number without a unit.
+func isBareNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && c != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTimeUnit parses the unit a bare number of a time value counts.
+func parseTimeUnit(s string) (time.Duration, error) {
+	unit, ok := _timeUnits[strings.ToLower(strings.TrimSpace(s))]
+	if !ok {
+		return 0, errInvalidUnit
+	}
+	return unit, nil
+}
+
+// formatClock returns d like a clock, M:SS or H:MM:SS with the minutes shown
+// in two digits as well.
+func formatClock(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	h, m, s := d/time.Hour, d%time.Hour/time.Minute, d%time.Minute/time.Second
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%02d:%02d", m, s)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		err  error
+	}{
+		{"1h20m30s", time.Hour + 20*time.Minute + 30*time.Second, nil},
+		{"1:30:00", time.Hour + 30*time.Minute, nil},
+		{"0:45", 45 * time.Second, nil},
+		{"25:00", 25 * time.Minute, nil},
+		{"90:00", 90 * time.Minute, nil},
+		{"1:5", 0, errInvalidTime},
+		{"1:60", 0, errInvalidTime},
+		{"1:00:00:00", 0, errInvalidTime},
+		{"-1:00", 0, errInvalidTime},
+		{"1:-5", 0, errInvalidTime},
+		{"ten", 0, errInvalidTime},
+		{"25", 25 * time.Minute, nil},
+		{"1.5", 90 * time.Second, nil},
+		{"1..5", 0, errInvalidTime},
+		{"1h + 30m", 90 * time.Minute, nil},
+		{"45m+10", 55 * time.Minute, nil},
+		{"1:00 + 0:30", 90 * time.Second, nil},
+		{"1h +", 0, errInvalidTime},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTime(tt.in, time.Minute)
+		if got != tt.want || err != tt.err {
+			t.Errorf("%s: want %v, %v got %v, %v", tt.in, tt.want, tt.err, got, err)
+		}
+	}
+}
+
+func TestParseTimeUnit(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		err  error
+	}{
+		{"s", time.Second, nil},
+		{"Minutes", time.Minute, nil},
+		{"h", time.Hour, nil},
+		{"days", 0, errInvalidUnit},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTimeUnit(tt.in)
+		if got != tt.want || err != tt.err {
+			t.Errorf("%s: want %v, %v got %v, %v", tt.in, tt.want, tt.err, got, err)
+		}
+	}
+}
+
+func TestFormatClock(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{0, "00:00"},
+		{2*time.Minute + 13*time.Second + 900*time.Millisecond, "02:13"},
+		{75 * time.Minute, "1:15:00"},
+		{26*time.Hour + 5*time.Second, "26:00:05"},
+	}
+
+	for _, tt := range tests {
+		if got := formatClock(tt.in); got != tt.want {
+			t.Errorf("%v: want %s got %s", tt.in, tt.want, got)
+		}
+	}
+}
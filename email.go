@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+var (
+	errNoSMTPServer = withExitCode(_exitInvalidArgs, errors.New("Set smtp_host in the config file to send emails"))
+)
+
+// port of the SMTP server unless configured, for submission with STARTTLS
+const _smtpPort = "587"
+
+// smtpConfig is the SMTP server the emails of -email are sent through.
+type smtpConfig struct {
+	host     string
+	port     string
+	user     string
+	password string
+	// sender of the emails, the user unless configured
+	from string
+}
+
+// sendEmail sends the message telling how the timer ended to the comma
+// separated addresses through the SMTP server. The login is only used on an
+// encrypted connection or with a server on the same machine.
+func sendEmail(s smtpConfig, to string, e timerEvent) error {
+	if s.host == "" {
+		return errNoSMTPServer
+	}
+	port := s.port
+	if port == "" {
+		port = _smtpPort
+	}
+	from := s.from
+	if from == "" {
+		from = s.user
+	}
+
+	var auth smtp.Auth
+	if s.user != "" {
+		auth = smtp.PlainAuth("", s.user, s.password, s.host)
+	}
+	recipients := strings.Split(to, ",")
+	for i := range recipients {
+		recipients[i] = strings.TrimSpace(recipients[i])
+	}
+	return smtp.SendMail(net.JoinHostPort(s.host, port), auth, from, recipients,
+		emailMessage(from, recipients, e, time.Now()))
+}
+
+// emailMessage returns the email from the sender to the recipients telling
+// how the timer ended, with the text as the subject and the times in the
+// body.
+func emailMessage(from string, to []string, e timerEvent, date time.Time) []byte {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", e.text()))
+	fmt.Fprintf(&msg, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "%s\r\n\r\n", e.text())
+	fmt.Fprintf(&msg, "Started:  %s\r\n", e.StartedAt.Format(time.RFC1123))
+	fmt.Fprintf(&msg, "Finished: %s\r\n", e.FinishedAt.Format(time.RFC1123))
+	fmt.Fprintf(&msg, "Duration: %v\r\n", time.Duration(e.Duration*float64(time.Second)).Round(time.Second))
+	return msg.Bytes()
+}
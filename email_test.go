@@ -0,0 +1,36 @@
+package main
+
+import (
+	"mime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmailMessage(t *testing.T) {
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	e := timerEvent{
+		Label: "Backup", Duration: 5400, StartedAt: start, FinishedAt: start.Add(90 * time.Minute),
+		Status: _statusExpired,
+	}
+	msg := string(emailMessage("timer@example.com", []string{"a@example.com", "b@example.com"}, e, e.FinishedAt))
+
+	for _, want := range []string{
+		"From: timer@example.com\r\n",
+		"To: a@example.com, b@example.com\r\n",
+		"Subject: " + mime.QEncoding.Encode("utf-8", "Backup: "+tr(_msgNotifyText)) + "\r\n",
+		"Date: Sat, 01 Mar 2025 10:30:00 +0000\r\n",
+		"\r\n\r\nBackup: ",
+		"Duration: 1h30m0s\r\n",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("email message has no %q:\n%s", want, msg)
+		}
+	}
+}
+
+func TestSendEmailWithoutServer(t *testing.T) {
+	if err := sendEmail(smtpConfig{}, "a@example.com", timerEvent{}); err != errNoSMTPServer {
+		t.Errorf("sendEmail without a server = %v, want %v", err, errNoSMTPServer)
+	}
+}
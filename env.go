@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// prefix of the environment variables setting the defaults of the flags and
+// the settings of the config file
+const _envPrefix = "TIMER_"
+
+// _envIgnored are the flags whose default is not taken from the environment.
+// TIMER_BACKGROUND marks the timer started by -background.
+var _envIgnored = map[string]bool{
+	"background": true,
+}
+
+// envName returns the name of the environment variable of the flag or the
+// config setting named name, like TIMER_NOTIFY_VIA for -notify-via.
+func envName(name string) string {
+	return _envPrefix + strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+// setEnvFlags sets the flags of fs which are not given on the command line
+// to the value of their environment variable, if it is set. Flags of a
+// single letter are set through their long name only, and a flag given
+// under any of its names keeps its value.
+func setEnvFlags(fs *flag.FlagSet) error {
+	given := make(map[flag.Value]bool)
+	fs.Visit(func(f *flag.Flag) {
+		given[f.Value] = true
+	})
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || len(f.Name) == 1 || given[f.Value] || _envIgnored[f.Name] {
+			return
+		}
+		name := envName(f.Name)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+		if e := fs.Set(f.Name, value); e != nil {
+			err = fmt.Errorf("%s: %v", name, e)
+		}
+	})
+	return err
+}
+
+// setEnv sets the settings of the config file whose environment variable is
+// set, like TIMER_DEFAULT_SOUND for default_sound, over those of the file.
+func (cfg *config) setEnv() error {
+	for _, kv := range os.Environ() {
+		i := strings.Index(kv, "=")
+		if i < 0 || !strings.HasPrefix(kv[:i], _envPrefix) {
+			continue
+		}
+		key := strings.ToLower(strings.TrimPrefix(kv[:i], _envPrefix))
+		// The other variables are those of the flags
+		if err := cfg.set(configEntry{key: key, value: kv[i+1:]}); err != nil && !errors.Is(err, errUnknownSetting) {
+			return err
+		}
+	}
+	return nil
+}
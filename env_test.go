@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPluginEnvFlags(t *testing.T) {
+	var label, message string
+	fs := flag.NewFlagSet("timer", flag.ContinueOnError)
+	fs.StringVar(&label, "label", "", "")
+	fs.StringVar(&message, "done-msg", "", "")
+	e := pluginEvent{Event: _statusExpired, Label: "Tea", Message: "Tea is ready"}
+	for _, kv := range e.env() {
+		i := strings.Index(kv, "=")
+		t.Setenv(kv[:i], kv[i+1:])
+	}
+	if err := setEnvFlags(fs); err != nil {
+		t.Fatal(err)
+	}
+	if label != "" || message != "" {
+		t.Errorf("a timer run by a plugin took the flags %q %q of the event", label, message)
+	}
+}
+
+func TestSetEnvFlags(t *testing.T) {
+	var (
+		notify, background bool
+		format, sound      string
+		grace              time.Duration
+	)
+	fs := flag.NewFlagSet("timer", flag.ContinueOnError)
+	fs.BoolVar(&notify, "notify", false, "")
+	fs.BoolVar(&notify, "n", false, "")
+	fs.BoolVar(&background, "background", false, "")
+	fs.StringVar(&format, "format", "text", "")
+	fs.StringVar(&sound, "sound", "", "")
+	fs.StringVar(&sound, "s", "", "")
+	fs.DurationVar(&grace, "grace", 10*time.Second, "")
+	if err := fs.Parse([]string{"-s", "Bell"}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("TIMER_NOTIFY", "1")
+	t.Setenv("TIMER_BACKGROUND", "1")
+	t.Setenv("TIMER_FORMAT", "json")
+	t.Setenv("TIMER_SOUND", "Chime")
+	t.Setenv("TIMER_S", "Alien")
+	if err := setEnvFlags(fs); err != nil {
+		t.Fatal(err)
+	}
+	if !notify || background || format != "json" || sound != "Bell" || grace != 10*time.Second {
+		t.Errorf("want true false json Bell 10s got %v %v %s %s %v", notify, background, format, sound, grace)
+	}
+
+	t.Setenv("TIMER_GRACE", "soon")
+	if err := setEnvFlags(fs); err == nil {
+		t.Error("TIMER_GRACE=soon: expected error")
+	}
+}
+
+func TestConfigSetEnv(t *testing.T) {
+	t.Setenv("TIMER_DEFAULT_SOUND", "Rooster")
+	t.Setenv("TIMER_POMODORO_GOAL", "6")
+	t.Setenv("TIMER_NOTIFY", "1")
+	cfg := &config{}
+	if err := cfg.setEnv(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.defaultSound != "Rooster" || cfg.pomodoroGoal != 6 {
+		t.Errorf("want Rooster 6 got %s %d", cfg.defaultSound, cfg.pomodoroGoal)
+	}
+
+	t.Setenv("TIMER_POMODORO_GOAL", "many")
+	if err := cfg.setEnv(); err == nil {
+		t.Error("TIMER_POMODORO_GOAL=many: expected error")
+	}
+}
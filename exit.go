@@ -0,0 +1,48 @@
+package main
+
+import "errors"
+
+// Exit statuses of the process, so that scripts can tell how the timer ended
+const (
+	_exitFailure     = 1
+	_exitInvalidArgs = 2
+	_exitSound       = 3
+	_exitNotify      = 4
+	// like a shell reports a command terminated by SIGINT
+	_exitCancelled = 130
+)
+
+// exitError is an error which terminates the process with its exit status.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode returns err terminating the process with the exit status
+// code, or nil if err is nil. An error which has an exit status already,
+// like the errors of invalid arguments, keeps it.
+func withExitCode(code int, err error) error {
+	var e *exitError
+	if err == nil || errors.As(err, &e) {
+		return err
+	}
+	return &exitError{code: code, err: err}
+}
+
+// exitCode returns the exit status of the process terminated by err. An
+// interrupt is a cancellation even while the sound is playing.
+func exitCode(err error) int {
+	var e *exitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errInterrupted):
+		return _exitCancelled
+	case errors.As(err, &e):
+		return e.code
+	}
+	return _exitFailure
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, 0},
+		{errors.New("disk full"), _exitFailure},
+		{errInvalidArgs, _exitInvalidArgs},
+		{fmt.Errorf("until: %w", errPastDate), _exitInvalidArgs},
+		{errInterrupted, _exitCancelled},
+		{withExitCode(_exitSound, errNoSoundPlayer), _exitSound},
+		{withExitCode(_exitNotify, errors.New("no daemon")), _exitNotify},
+		{withExitCode(_exitSound, errInterrupted), _exitCancelled},
+		{withExitCode(_exitInvalidArgs, errors.New("bad time")), _exitInvalidArgs},
+		{withExitCode(_exitSound, errSoundNotFound), _exitInvalidArgs},
+		{withExitCode(_exitSound, fmt.Errorf("timer: %w", errUnknownStyle)), _exitInvalidArgs},
+	}
+	for _, test := range tests {
+		if got := exitCode(test.err); got != test.want {
+			t.Errorf("exitCode(%v) = %d, want %d", test.err, got, test.want)
+		}
+	}
+	if withExitCode(_exitSound, nil) != nil {
+		t.Error("withExitCode(nil) is not nil")
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// how long -flash flashes the terminal, and how often it switches
+	// between the normal and the reverse video
+	_flashDuration = 3 * time.Second
+	_flashInterval = 250 * time.Millisecond
+)
+
+// flash processes -flash.
+// Flash the terminal by switching it between the normal and the reverse
+// video for a few seconds in the background, or until the command is
+// interrupted. Terminals without ANSI escape sequences are shown a banner of
+// the text instead.
+func (cmd *Cmd) flash(text string) {
+	if !cmd.ansi {
+		fmt.Println(banner(text))
+		return
+	}
+	cmd.sendHook("flash", func() error {
+		// The normal video is restored however the flashing ends
+		defer fmt.Print("\x1b[?5l")
+		ticker := time.NewTicker(_flashInterval)
+		defer ticker.Stop()
+		stop := time.After(_flashDuration)
+		for reverse := true; ; reverse = !reverse {
+			if reverse {
+				fmt.Print("\x1b[?5h")
+			} else {
+				fmt.Print("\x1b[?5l")
+			}
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return nil
+			case <-cmd.ctx.Done():
+				return nil
+			}
+		}
+	})
+}
+
+// banner returns the text framed by asterisks over three lines.
+func banner(text string) string {
+	line := strings.Repeat("*", len([]rune(text))+8)
+	return line + "\n*** " + text + " ***\n" + line
+}
@@ -0,0 +1,10 @@
+package main
+
+import "testing"
+
+func TestBanner(t *testing.T) {
+	want := "**************\n*** Tea é! ***\n**************"
+	if got := banner("Tea é!"); got != want {
+		t.Errorf("want\n%s\ngot\n%s", want, got)
+	}
+}
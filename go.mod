@@ -1,13 +1,20 @@
 module github.com/heisantosh/timer
 
-go 1.13
+go 1.21
 
 require (
 	github.com/gen2brain/beeep v0.0.0-20190719094215-ece0cb67ca77
+	github.com/yuin/gopher-lua v1.1.1
+	go.etcd.io/bbolt v1.3.7
+	golang.org/x/sys v0.4.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
 	github.com/godbus/dbus v4.1.0+incompatible // indirect
+	github.com/gopherjs/gopherjs v0.0.0-20180825215210-0210a2f0f73c // indirect
 	github.com/gopherjs/gopherwasm v1.1.0 // indirect
 	github.com/nu7hatch/gouuid v0.0.0-20131221200532-179d4d0c4d8d // indirect
 	github.com/tadvi/systray v0.0.0-20190226123456-11a2b8fa57af // indirect
-	golang.org/x/sys v0.0.0-20190429190828-d89cdac9e872 // indirect
 	gopkg.in/toast.v1 v1.0.0-20180812000517-0a84660828b2 // indirect
 )
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Kinds of the timers recorded in the history and statuses of their end
+const (
+	_historyPomodoro = "pomodoro"
+	_historyTimer    = "timer"
+	_historyTrack    = "track"
+	_statusExpired   = "expired"
+	_statusCancelled = "cancelled"
+	// status of a tracked session, which has no expiry
+	_statusStopped = "stopped"
+)
+
+var (
+	// bucket of the entries of the history, keyed by start
+	_historyTimers = []byte("timers")
+	// bucket of a bucket per label, with the keys of the entries of the label
+	_historyLabels = []byte("labels")
+)
+
+// how long opening the history waits for another timer writing to it
+const _historyLockTimeout = 5 * time.Second
+
+// historyEntry is a finished timer recorded in the history.
+type historyEntry struct {
+	Kind  string `json:"kind"`
+	Label string `json:"label,omitempty"`
+	// time the timer started and its duration in seconds
+	Start    time.Time `json:"start"`
+	Duration float64   `json:"duration"`
+	// seconds from the expiry until the timer was stopped, with -overtime
+	Overtime float64 `json:"overtime,omitempty"`
+	// status of a timer which did not complete and the seconds it ran
+	Status  string  `json:"status,omitempty"`
+	Elapsed float64 `json:"elapsed,omitempty"`
+}
+
+// historyQuery selects the entries of the history started from from until
+// to, of the kind and with the label. Zero fields select every entry.
+type historyQuery struct {
+	from, to time.Time
+	kind     string
+	label    string
+}
+
+// getHistoryFile returns the location of the history database.
+func getHistoryFile() string {
+	return filepath.Join(getConfigDir(), "history.db")
+}
+
+// getLegacyHistoryFile returns the location of the history file of earlier
+// versions, which has one JSON entry per line.
+func getLegacyHistoryFile() string {
+	return filepath.Join(getConfigDir(), "history.jsonl")
+}
+
+// openHistory opens the history database, creating it if needed. The
+// entries of the history file of earlier versions are moved to it.
+func openHistory() (*bolt.DB, error) {
+	if err := os.MkdirAll(getConfigDir(), 0776); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(getHistoryFile(), 0644, &bolt.Options{Timeout: _historyLockTimeout})
+	if err != nil {
+		return nil, err
+	}
+	if err := migrateHistory(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("moving %s to the history database: %v", getLegacyHistoryFile(), err)
+	}
+	return db, nil
+}
+
+// migrateHistory adds the entries of the legacy history file to the
+// database, and keeps the file with the .bak extension.
+func migrateHistory(db *bolt.DB) error {
+	legacy := getLegacyHistoryFile()
+	entries, err := loadLegacyHistory(legacy)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, e := range entries {
+			if err := putHistory(tx, e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return os.Rename(legacy, legacy+".bak")
+}
+
+// appendHistory records the entry in the history.
+func appendHistory(e historyEntry) error {
+	db, err := openHistory()
+	if err != nil {
+		return err
+	}
+	if err := db.Update(func(tx *bolt.Tx) error { return putHistory(tx, e) }); err != nil {
+		db.Close()
+		return err
+	}
+	return db.Close()
+}
+
+// putHistory adds the entry to the history, and its key to the bucket of
+// its label.
+func putHistory(tx *bolt.Tx, e historyEntry) error {
+	timers, err := tx.CreateBucketIfNotExists(_historyTimers)
+	if err != nil {
+		return err
+	}
+	seq, err := timers.NextSequence()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	key := historyKey(e.Start, seq)
+	if err := timers.Put(key, data); err != nil {
+		return err
+	}
+	if e.Label == "" {
+		return nil
+	}
+
+	labels, err := tx.CreateBucketIfNotExists(_historyLabels)
+	if err != nil {
+		return err
+	}
+	label, err := labels.CreateBucketIfNotExists([]byte(e.Label))
+	if err != nil {
+		return err
+	}
+	return label.Put(key, nil)
+}
+
+// historyKey returns the key of an entry started at start, which sorts the
+// entries by start. The sequence number tells apart entries of the same
+// start.
+func historyKey(start time.Time, seq uint64) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key, uint64(start.UnixNano()))
+	binary.BigEndian.PutUint64(key[8:], seq)
+	return key
+}
+
+// queryHistory returns the entries of the history selected by the query
+// sorted by start. A missing history is an empty history.
+func queryHistory(q historyQuery) ([]historyEntry, error) {
+	if _, err := os.Stat(getHistoryFile()); os.IsNotExist(err) {
+		if _, err := os.Stat(getLegacyHistoryFile()); os.IsNotExist(err) {
+			return nil, nil
+		}
+	}
+	db, err := openHistory()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var entries []historyEntry
+	err = db.View(func(tx *bolt.Tx) error {
+		timers := tx.Bucket(_historyTimers)
+		if timers == nil {
+			return nil
+		}
+		// The bucket of the label has the keys of its entries, or all of
+		// them are walked through
+		keys := timers
+		if q.label != "" {
+			if keys = tx.Bucket(_historyLabels); keys != nil {
+				keys = keys.Bucket([]byte(q.label))
+			}
+			if keys == nil {
+				return nil
+			}
+		}
+
+		c := keys.Cursor()
+		var end []byte
+		if !q.to.IsZero() {
+			end = historyKey(q.to, 0)
+		}
+		k, _ := c.First()
+		if !q.from.IsZero() {
+			k, _ = c.Seek(historyKey(q.from, 0))
+		}
+		for ; k != nil; k, _ = c.Next() {
+			if end != nil && string(k) >= string(end) {
+				break
+			}
+			var e historyEntry
+			if err := json.Unmarshal(timers.Get(k), &e); err != nil {
+				return fmt.Errorf("history entry of %v: %v", time.Unix(0, int64(binary.BigEndian.Uint64(k))), err)
+			}
+			if q.kind == "" || e.Kind == q.kind {
+				entries = append(entries, e)
+			}
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// history processes the command (history [FROM [TO]]).
+// List the timers of the history started from the date FROM until the end of
+// the date TO, only those labelled -label with -label, in the -format text,
+// json or csv.
+func (cmd *Cmd) history(args []string) error {
+	if len(args) > 2 {
+		fmt.Println("Expected at most the dates FROM and TO, like 2024-03-01")
+		return errInvalidArgs
+	}
+	switch cmd.args.format {
+	case "text", "json", "csv":
+	default:
+		fmt.Println(errUnknownFormat)
+		return errUnknownFormat
+	}
+
+	q := historyQuery{label: cmd.args.label}
+	for i, arg := range args {
+		day, err := time.ParseInLocation(_dayLayout, arg, time.Local)
+		if err != nil {
+			fmt.Println("Invalid date", arg, "expected a date like 2024-03-01")
+			return errInvalidArgs
+		}
+		if i == 0 {
+			q.from = day
+		} else {
+			q.to = day.AddDate(0, 0, 1)
+		}
+	}
+
+	entries, err := queryHistory(q)
+	if err != nil {
+		return fail("Error reading the history", err)
+	}
+	if err := writeHistory(os.Stdout, entries, cmd.args.format); err != nil {
+		return fail("Error writing the history", err)
+	}
+	return nil
+}
+
+// writeHistory writes the entries in the format, text, json or csv.
+func writeHistory(w io.Writer, entries []historyEntry, format string) error {
+	switch format {
+	case "json":
+		if entries == nil {
+			entries = []historyEntry{}
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"start", "kind", "label", "duration", "status", "elapsed", "overtime"})
+		for _, e := range entries {
+			cw.Write([]string{
+				e.Start.Format(time.RFC3339), e.Kind, e.Label, strconv.FormatFloat(e.Duration, 'f', -1, 64),
+				e.status(), strconv.FormatFloat(e.Elapsed, 'f', -1, 64), strconv.FormatFloat(e.Overtime, 'f', -1, 64),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "START\tKIND\tDURATION\tSTATUS\tLABEL")
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%v\t%s\t%s\n", e.Start.Local().Format("2006-01-02 15:04"), e.Kind,
+			seconds(e.Duration), e.status(), e.Label)
+	}
+	return tw.Flush()
+}
+
+// status returns the status of the entry, entries of earlier versions have
+// none and all expired.
+func (e historyEntry) status() string {
+	if e.Status == "" {
+		return _statusExpired
+	}
+	return e.Status
+}
+
+// seconds returns the duration of s seconds rounded to the second.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second)).Round(time.Second)
+}
+
+// loadLegacyHistory reads the entries of the history file of earlier
+// versions.
+func loadLegacyHistory(file string) ([]historyEntry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []historyEntry
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e historyEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("history line %d: %v", n, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// Identifiers of the messages shown to the user
+const (
+	_msgHelp         = "help"
+	_msgProgress     = "progress"
+	_msgExpired      = "expired"
+	_msgInterrupted  = "interrupted"
+	_msgNotifyTitle  = "notify-title"
+	_msgNotifyText   = "notify-text"
+	_msgBrowseHeader = "browse-header"
+	_msgNext         = "next"
+	_msgStopped      = "stopped"
+	_msgRemaining    = "remaining"
+)
+
+// _catalogs holds the messages of each language. A message missing in a
+// language is shown in English. The progress message is a format receiving
+// the percentage, the passed, the remaining and the total time, the next
+// message the label of the next timer of a batch or sequence and the stopped
+// message the passed and the remaining time of an interrupted timer and the
+// remaining message the time left until a timer expires.
+var _catalogs = map[string]map[string]string{
+	"en": {
+		_msgHelp:         _helpText,
+		_msgProgress:     "%3d%% [passed: %v, remaining: %v, total: %v]",
+		_msgExpired:      "Timer expired!",
+		_msgInterrupted:  "Timer interrupted",
+		_msgNotifyTitle:  "Timer",
+		_msgNotifyText:   "Time is expired!",
+		_msgBrowseHeader: "Sounds (up/down move, enter preview, d delete, r rename, q quit)",
+		_msgNext:         "Next: %s",
+		_msgStopped:      "Timer interrupted after %v, %v remaining",
+		_msgRemaining:    "%v remaining",
+	},
+	"de": {
+		_msgProgress:     "%3d%% [vergangen: %v, verbleibend: %v, gesamt: %v]",
+		_msgExpired:      "Timer abgelaufen!",
+		_msgInterrupted:  "Timer abgebrochen",
+		_msgNotifyTitle:  "Timer",
+		_msgNotifyText:   "Die Zeit ist abgelaufen!",
+		_msgBrowseHeader: "Klänge (auf/ab bewegen, Enter anhören, d löschen, r umbenennen, q beenden)",
+		_msgNext:         "Als Nächstes: %s",
+		_msgStopped:      "Timer nach %v abgebrochen, %v verbleibend",
+		_msgRemaining:    "noch %v",
+	},
+	"es": {
+		_msgProgress:     "%3d%% [transcurrido: %v, restante: %v, total: %v]",
+		_msgExpired:      "¡Temporizador terminado!",
+		_msgInterrupted:  "Temporizador interrumpido",
+		_msgNotifyTitle:  "Temporizador",
+		_msgNotifyText:   "¡Se acabó el tiempo!",
+		_msgBrowseHeader: "Sonidos (arriba/abajo mover, enter escuchar, d borrar, r renombrar, q salir)",
+		_msgNext:         "A continuación: %s",
+		_msgStopped:      "Temporizador interrumpido tras %v, quedaban %v",
+		_msgRemaining:    "quedan %v",
+	},
+	"fr": {
+		_msgProgress:     "%3d%% [écoulé : %v, restant : %v, total : %v]",
+		_msgExpired:      "Minuteur terminé !",
+		_msgInterrupted:  "Minuteur interrompu",
+		_msgNotifyTitle:  "Minuteur",
+		_msgNotifyText:   "Le temps est écoulé !",
+		_msgBrowseHeader: "Sons (haut/bas déplacer, entrée écouter, d supprimer, r renommer, q quitter)",
+		_msgNext:         "Ensuite : %s",
+		_msgStopped:      "Minuteur interrompu après %v, il restait %v",
+		_msgRemaining:    "encore %v",
+	},
+}
+
+// _language is the language of the messages, detected from the locale.
+var _language = detectLanguage()
+
+// tr returns the message with the identifier id in the language of the user.
+func tr(id string) string {
+	if msg, ok := _catalogs[_language][id]; ok {
+		return msg
+	}
+	return _catalogs["en"][id]
+}
+
+// detectLanguage returns the language of the locale of the user. The locale
+// is read from TIMER_LANG, then LC_ALL, LC_MESSAGES and LANG as on Unix, and
+// finally from the system. Languages without a catalog fall back to English.
+func detectLanguage() string {
+	for _, name := range []string{_timerLang, "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale := os.Getenv(name); locale != "" {
+			return localeLanguage(locale)
+		}
+	}
+	return localeLanguage(systemLocale())
+}
+
+// localeLanguage returns the language of a locale like de_DE.UTF-8 or de-DE,
+// or en if there is no catalog for it.
+func localeLanguage(locale string) string {
+	lang := strings.ToLower(locale)
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := _catalogs[lang]; !ok {
+		return "en"
+	}
+	return lang
+}
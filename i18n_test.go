@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestLocaleLanguage(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"de_DE.UTF-8", "de"},
+		{"fr-FR", "fr"},
+		{"es", "es"},
+		{"C", "en"},
+		{"ja_JP.UTF-8", "en"},
+		{"", "en"},
+	}
+
+	for _, tt := range tests {
+		if got := localeLanguage(tt.in); got != tt.want {
+			t.Errorf("%s: want %s got %s", tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestCatalogs(t *testing.T) {
+	for lang, catalog := range _catalogs {
+		for id := range catalog {
+			if _, ok := _catalogs["en"][id]; !ok {
+				t.Errorf("%s: message %s is missing in English", lang, id)
+			}
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// layout of the UTC date-times of iCalendar
+const _icsTime = "20060102T150405Z"
+
+// icsEvent is an event of an iCalendar file, with an alarm displaying the
+// summary when it starts.
+type icsEvent struct {
+	uid     string
+	summary string
+	start   time.Time
+}
+
+// writeICS writes the events as an iCalendar file, stamped with now.
+func writeICS(w io.Writer, events []icsEvent, now time.Time) error {
+	lines := []string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//heisantosh//timer//EN"}
+	for _, e := range events {
+		start := e.start.UTC().Format(_icsTime)
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+e.uid,
+			"DTSTAMP:"+now.UTC().Format(_icsTime),
+			"DTSTART:"+start,
+			"DTEND:"+start,
+			"SUMMARY:"+icsEscape(e.summary),
+			"BEGIN:VALARM",
+			"ACTION:DISPLAY",
+			"TRIGGER;RELATED=START:PT0S",
+			"DESCRIPTION:"+icsEscape(e.summary),
+			"END:VALARM",
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, icsFold(line)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// icsEscape escapes the text of a property value.
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// icsFold returns the content line ended by CRLF, folded into lines of at
+// most 75 octets without splitting a character.
+func icsFold(line string) string {
+	var b strings.Builder
+	width := 75
+	for len(line) > width {
+		cut := width
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		fmt.Fprintf(&b, "%s\r\n ", line[:cut])
+		line = line[cut:]
+		// The leading space of a continuation line counts
+		width = 74
+	}
+	b.WriteString(line + "\r\n")
+	return b.String()
+}
+
+// parseICS returns the events of the iCalendar data which start at a time of
+// day. All-day events and cancelled events are skipped, and recurring events
+// are read as their first occurrence.
+func parseICS(data string) ([]icsEvent, error) {
+	// Continuation lines start with a space or a tab
+	data = strings.NewReplacer("\r\n ", "", "\r\n\t", "", "\n ", "", "\n\t", "").Replace(data)
+
+	var (
+		events []icsEvent
+		e      icsEvent
+		// depth of the components inside the current event, like alarms
+		depth    int
+		inEvent  bool
+		skip     bool
+		hasStart bool
+	)
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		name, params, value, ok := icsProperty(line)
+		if !ok {
+			return nil, fmt.Errorf("invalid iCalendar line %q", line)
+		}
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT" && !inEvent:
+			inEvent, e, skip, hasStart, depth = true, icsEvent{}, false, false, 0
+		case !inEvent:
+		case name == "BEGIN":
+			depth++
+		case name == "END" && depth > 0:
+			depth--
+		case name == "END" && value == "VEVENT":
+			inEvent = false
+			if hasStart && !skip {
+				events = append(events, e)
+			}
+		case depth > 0:
+		case name == "UID":
+			e.uid = value
+		case name == "SUMMARY":
+			e.summary = icsUnescape(value)
+		case name == "STATUS":
+			skip = skip || value == "CANCELLED"
+		case name == "DTSTART":
+			if params["VALUE"] == "DATE" {
+				skip = true
+				continue
+			}
+			start, err := parseICSTime(value, params["TZID"])
+			if err != nil {
+				return nil, fmt.Errorf("invalid iCalendar start %s: %v", value, err)
+			}
+			e.start, hasStart = start, true
+		}
+	}
+	return events, nil
+}
+
+// icsProperty splits the content line into the name, the parameters and the
+// value of the property. Quoted parameter values may contain a colon.
+func icsProperty(line string) (string, map[string]string, string, bool) {
+	colon, quoted := -1, false
+	for i := 0; i < len(line) && colon < 0; i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ':':
+			if !quoted {
+				colon = i
+			}
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params := make(map[string]string)
+	for _, p := range parts[1:] {
+		if eq := strings.Index(p, "="); eq > 0 {
+			params[strings.ToUpper(p[:eq])] = strings.Trim(p[eq+1:], `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:], true
+}
+
+// parseICSTime parses a date-time of iCalendar, in UTC if it ends with Z,
+// in the time zone tzid if given and in local time otherwise.
+func parseICSTime(value, tzid string) (time.Time, error) {
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse(_icsTime, value)
+	}
+	loc := time.Local
+	if tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	return time.ParseInLocation("20060102T150405", value, loc)
+}
+
+// icsUnescape returns the text of an escaped property value.
+func icsUnescape(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(s)
+}
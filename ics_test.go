@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestICSFold(t *testing.T) {
+	short := "SUMMARY:Tea"
+	if got := icsFold(short); got != short+"\r\n" {
+		t.Errorf("want %q got %q", short+"\r\n", got)
+	}
+
+	long := "SUMMARY:" + strings.Repeat("é", 60)
+	got := icsFold(long)
+	lines := strings.Split(strings.TrimSuffix(got, "\r\n"), "\r\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], " ") {
+		t.Fatalf("want 2 folded lines got %q", got)
+	}
+	for _, line := range lines {
+		if len(line) > 75 {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+	}
+	if unfolded := lines[0] + lines[1][1:]; unfolded != long {
+		t.Errorf("want %q unfolded got %q", long, unfolded)
+	}
+}
+
+func TestWriteICS(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	var b bytes.Buffer
+	if err := writeICS(&b, []icsEvent{{uid: "1-2@timer", summary: "Tea, green; hot", start: now.Add(4 * time.Minute)}}, now); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//heisantosh//timer//EN",
+		"BEGIN:VEVENT", "UID:1-2@timer", "DTSTAMP:20240301T090000Z", "DTSTART:20240301T090400Z",
+		"DTEND:20240301T090400Z", `SUMMARY:Tea\, green\; hot`,
+		"BEGIN:VALARM", "ACTION:DISPLAY", "TRIGGER;RELATED=START:PT0S", `DESCRIPTION:Tea\, green\; hot`, "END:VALARM",
+		"END:VEVENT", "END:VCALENDAR", "",
+	}, "\r\n")
+	if got := b.String(); got != want {
+		t.Errorf("want %q got %q", want, got)
+	}
+}
+
+func TestAlarmEvents(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	events := alarmEvents([]backgroundState{
+		{PID: 10, Started: now, Label: "Laundry", Ends: now.Add(time.Hour)},
+		{PID: 11, Started: now},
+		{PID: 12, Started: now, Ends: now.Add(time.Minute)},
+	})
+	if len(events) != 2 {
+		t.Fatalf("want 2 events got %+v", events)
+	}
+	if events[0].uid != "12-1709283600@timer" || events[0].summary != tr(_msgExpired) {
+		t.Errorf("first event %+v", events[0])
+	}
+	if events[1].summary != "Laundry" || !events[1].start.Equal(now.Add(time.Hour)) {
+		t.Errorf("second event %+v", events[1])
+	}
+}
+
+func TestParseICS(t *testing.T) {
+	data := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:standup",
+		"DTSTART:20240301T090000Z",
+		"SUMMARY:Stand-up\\, team",
+		"  A",
+		"BEGIN:VALARM",
+		"SUMMARY:Not the event",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:review",
+		`DTSTART;TZID="America/New_York":20240301T140000`,
+		"SUMMARY:Review",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:holiday",
+		"DTSTART;VALUE=DATE:20240302",
+		"SUMMARY:Holiday",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:cancelled",
+		"DTSTART:20240301T100000Z",
+		"STATUS:CANCELLED",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	events, err := parseICS(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone database:", err)
+	}
+	want := []icsEvent{
+		{uid: "standup", summary: "Stand-up, team A", start: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)},
+		{uid: "review", summary: "Review", start: time.Date(2024, 3, 1, 14, 0, 0, 0, ny)},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("want %+v got %+v", want, events)
+	}
+	for i := range want {
+		if events[i].uid != want[i].uid || events[i].summary != want[i].summary || !events[i].start.Equal(want[i].start) {
+			t.Errorf("want %+v got %+v", want[i], events[i])
+		}
+	}
+
+	if _, err := parseICS("BEGIN:VEVENT\r\nDTSTART:tomorrow\r\nEND:VEVENT"); err == nil {
+		t.Error("invalid start: expected error")
+	}
+}
+
+func TestNextEvent(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	events := []icsEvent{
+		{uid: "late", start: now.Add(5 * time.Minute)},
+		{uid: "review", start: now.Add(2 * time.Hour)},
+		{uid: "standup", start: now.Add(30 * time.Minute)},
+		{uid: "due", start: now.Add(10*time.Minute - 30*time.Second)},
+	}
+
+	alerted := make(map[string]bool)
+	e, ok := nextEvent(events, now, 10*time.Minute, alerted)
+	if !ok || e.uid != "due" {
+		t.Fatalf("want due got %+v %v", e, ok)
+	}
+	alerted[eventKey(e)] = true
+	if e, ok = nextEvent(events, now, 10*time.Minute, alerted); !ok || e.uid != "standup" {
+		t.Errorf("want standup got %+v %v", e, ok)
+	}
+	if _, ok = nextEvent(events, now.Add(3*time.Hour), 10*time.Minute, alerted); ok {
+		t.Error("want no event after the last one")
+	}
+}
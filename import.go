@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// importICS processes the command (import).
+// Alert -before each upcoming event of the iCalendar file or URL given with
+// -ics, with the sound given with -sound and a notification. The events are
+// read again every -refresh, until the command is interrupted.
+func (cmd *Cmd) importICS(args []string) error {
+	if len(args) != 0 || cmd.args.ics == "" {
+		fmt.Println("Expected the iCalendar file or URL with -ics")
+		return errInvalidArgs
+	}
+	sound := cmd.args.sound
+	if cmd.args.withSound && sound == "" {
+		sound = _defaultSoundName
+	}
+	if sound != "" {
+		var err error
+		if sound, err = cmd.selectSound(sound); err != nil {
+			return err
+		}
+	}
+
+	alerted := make(map[string]bool)
+	var next icsEvent
+	for {
+		events, err := readICS(cmd.ctx, cmd.args.ics)
+		if err != nil {
+			// The feed may be back on the next refresh
+			fmt.Println("Error reading the events:", err)
+		} else if e, ok := nextEvent(events, time.Now(), cmd.args.before, alerted); !ok {
+			next = icsEvent{}
+		} else if e != next {
+			next = e
+			fmt.Printf("Next: %s at %s, alert at %s\n", e.summary, e.start.Local().Format("Mon 15:04"),
+				e.start.Add(-cmd.args.before).Local().Format("15:04"))
+		}
+
+		wait := cmd.args.refresh
+		if !next.start.IsZero() {
+			if until := time.Until(next.start.Add(-cmd.args.before)); until < wait {
+				wait = until
+			}
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-cmd.ctx.Done():
+			t.Stop()
+			fmt.Println(tr(_msgInterrupted))
+			return errInterrupted
+		case <-t.C:
+		}
+		if next.start.IsZero() || time.Now().Before(next.start.Add(-cmd.args.before)) {
+			continue
+		}
+
+		alerted[eventKey(next)] = true
+		cmd.label, cmd.started, cmd.expired = next.summary, time.Now(), time.Now()
+		text := fmt.Sprintf("%s at %s", next.summary, next.start.Local().Format("15:04"))
+		fmt.Println(text)
+		cmd.sendHooks(_statusExpired, cmd.started, next.start)
+		if err := cmd.batchAlert(batchTimer{sound: sound, notify: true}, text, true); errors.Is(err, errInterrupted) {
+			return err
+		} else if err != nil {
+			// The next events are alerted all the same
+			slog.Warn("alerting the event failed", "event", next.summary, "err", err)
+		}
+		next = icsEvent{}
+	}
+}
+
+// nextEvent returns the first event whose alert, before its start, is due
+// after now and which was not alerted yet.
+func nextEvent(events []icsEvent, now time.Time, before time.Duration, alerted map[string]bool) (icsEvent, bool) {
+	var next icsEvent
+	found := false
+	for _, e := range events {
+		// An alert due a moment ago is late but not missed
+		if alerted[eventKey(e)] || e.start.Add(-before).Before(now.Add(-time.Minute)) {
+			continue
+		}
+		if !found || e.start.Before(next.start) {
+			next, found = e, true
+		}
+	}
+	return next, found
+}
+
+// eventKey returns the key telling an occurrence of an event apart.
+func eventKey(e icsEvent) string {
+	return e.uid + "@" + e.start.UTC().Format(_icsTime)
+}
+
+// readICS reads the events of the iCalendar file, or of the URL if src is an
+// http, https or webcal URL.
+func readICS(ctx context.Context, src string) ([]icsEvent, error) {
+	var data []byte
+	if strings.HasPrefix(src, "webcal://") {
+		src = "https://" + strings.TrimPrefix(src, "webcal://")
+	}
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		ctx, cancel := context.WithTimeout(ctx, _hookTimeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s: %s", src, resp.Status)
+		}
+		if data, err = io.ReadAll(resp.Body); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if data, err = ioutil.ReadFile(src); err != nil {
+			return nil, err
+		}
+	}
+	return parseICS(string(data))
+}
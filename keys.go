@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	// _keys receives the keys pressed on the terminal, read by a single
+	// goroutine started on first use so that no key is lost to a reader
+	// which is no longer waiting.
+	_keys     = make(chan string)
+	_keysOnce sync.Once
+)
+
+// waitKey shows the prompt and waits until a key is pressed on the
+// terminal, which is returned. With a timeout greater than 0 an empty key
+// is returned when no key is pressed in time. The terminal takes single keys
+// only while waiting, so that typing ahead still echoes.
+func (cmd *Cmd) waitKey(prompt string, timeout time.Duration) (string, error) {
+	keys, restore := terminalKeys()
+	defer restore()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		expired = t.C
+	}
+
+	fmt.Println(prompt)
+	select {
+	case <-expired:
+		return "", nil
+	case key, ok := <-keys:
+		if !ok {
+			// Standard input is closed, nobody can press a key
+			fmt.Println(errNotTerminal)
+			return "", errNotTerminal
+		}
+		return key, nil
+	case <-cmd.ctx.Done():
+		fmt.Println(tr(_msgInterrupted))
+		return "", errInterrupted
+	}
+}
+
+// terminalKeys makes the terminal take single keys and returns the channel
+// receiving the keys pressed, along with the function restoring the terminal.
+// The channel is closed when standard input is.
+func terminalKeys() (<-chan string, func()) {
+	restore := func() {}
+	if r, err := rawInput(os.Stdin.Fd()); err == nil {
+		restore = r
+	}
+	_keysOnce.Do(func() { go readKeys(_keys) })
+	return _keys, restore
+}
+
+// isTerminal reports whether standard input is a terminal, on which keys
+// can be pressed.
+func isTerminal() bool {
+	restore, err := rawInput(os.Stdin.Fd())
+	if err != nil {
+		return false
+	}
+	restore()
+	return true
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// _audioExtensions is the set of file extensions recognized as audio files
+// when adding sounds in bulk.
+var _audioExtensions = map[string]bool{
+	".aac": true, ".aif": true, ".aiff": true, ".flac": true, ".m4a": true,
+	".mid": true, ".midi": true, ".mp3": true, ".oga": true, ".ogg": true,
+	".opus": true, ".wav": true, ".wma": true,
+}
+
+// files at least this large are copied with a progress indicator, 10 MiB
+const _progressMinSize = 10 << 20
+
+// soundMeta is the metadata stored for a sound in the sound library.
+type soundMeta struct {
+	// Command used to play this sound instead of the global sound command
+	Command string `json:"command,omitempty"`
+	// Path is the absolute location of a linked sound, which is not copied
+	// into the sounds directory
+	Path string `json:"path,omitempty"`
+}
+
+// librarySoundsDir returns the directory storing added sounds. The directory
+// from TIMER_SOUNDS_DIR takes precedence over the sounds_dir setting of the
+// config file, which takes precedence over the default directory.
+func librarySoundsDir(cfg *config) string {
+	if dir := os.Getenv(_timerSoundsDir); dir != "" {
+		return expandPath(dir)
+	}
+	if cfg.soundsDir != "" {
+		return expandPath(cfg.soundsDir)
+	}
+	return getSoundsDir()
+}
+
+// expandPath replaces environment variables and a leading ~ in the path.
+func expandPath(p string) string {
+	p = os.ExpandEnv(p)
+	if p == "~" || strings.HasPrefix(p, "~/") || strings.HasPrefix(p, `~\`) {
+		p = filepath.Join(os.Getenv("HOME"), p[1:])
+	}
+	return filepath.Clean(p)
+}
+
+// getLibraryFile returns the file storing the metadata of the sound library
+// in the sounds directory soundsDir. The one of the default directory is kept
+// next to it, and those of the directories moved with TIMER_SOUNDS_DIR or
+// sounds_dir in the config directory, named after a hash of the directory,
+// so that it is not listed as a sound nor shared with another directory.
+func getLibraryFile(soundsDir string) string {
+	if filepath.Clean(soundsDir) == filepath.Clean(getSoundsDir()) {
+		return filepath.Join(filepath.Dir(soundsDir), "sounds.json")
+	}
+	if abs, err := filepath.Abs(soundsDir); err == nil {
+		soundsDir = abs
+	}
+	sum := sha256.Sum256([]byte(soundsDir))
+	return filepath.Join(getConfigDir(), fmt.Sprintf("sounds-%x.json", sum[:6]))
+}
+
+// loadLibrary reads the sound metadata keyed by the name of the sound from
+// the file. A missing metadata file is an empty library.
+func loadLibrary(file string) (map[string]soundMeta, error) {
+	meta := make(map[string]soundMeta)
+
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return meta, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// saveLibrary writes the sound metadata keyed by the name of the sound.
+func (cmd *Cmd) saveLibrary() error {
+	data, err := json.MarshalIndent(cmd.meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	file := getLibraryFile(cmd.soundsDir)
+	// The config directory of a moved sounds directory may not exist yet
+	if err := os.MkdirAll(filepath.Dir(file), 0776); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0644)
+}
+
+// isAudioFile reports whether the file has the extension of an audio file.
+func isAudioFile(file string) bool {
+	return _audioExtensions[strings.ToLower(filepath.Ext(file))]
+}
+
+// soundFiles returns the files matched by the glob pattern or contained in the
+// directory loc. It returns nil if loc is a single file or URL.
+func soundFiles(loc string) ([]string, error) {
+	if isURL(loc) {
+		return nil, nil
+	}
+
+	// Not nil even without any match, so that loc is not taken for a file
+	files := []string{}
+	if strings.ContainsAny(loc, "*?[") {
+		matches, err := filepath.Glob(loc)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			if fi, err := os.Stat(m); err == nil && !fi.IsDir() {
+				files = append(files, m)
+			}
+		}
+		return files, nil
+	}
+
+	fi, err := os.Stat(loc)
+	if err != nil || !fi.IsDir() {
+		// Let adding the file report the error
+		return nil, nil
+	}
+
+	entries, err := ioutil.ReadDir(loc)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			files = append(files, filepath.Join(loc, e.Name()))
+		}
+	}
+	return files, nil
+}
+
+// copyFile copies the file src to dst keeping its permissions and
+// modification time. The copy is streamed so that large files are not held in
+// memory, and its progress is shown for files of at least _progressMinSize.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = in
+	if fi.Size() >= _progressMinSize {
+		p := &progressWriter{name: filepath.Base(src), total: fi.Size(), last: -1}
+		r = io.TeeReader(in, p)
+		defer fmt.Println()
+	}
+
+	_, err = io.Copy(out, r)
+	if e := out.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		os.Remove(dst)
+		return err
+	}
+
+	if err := os.Chmod(dst, fi.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, fi.ModTime(), fi.ModTime())
+}
+
+// progressWriter shows the percentage of the total number of bytes which
+// have been written to it.
+type progressWriter struct {
+	name    string
+	total   int64
+	written int64
+	// last percentage shown
+	last int
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.written += int64(len(b))
+	if pc := int(p.written * 100 / p.total); pc != p.last {
+		fmt.Printf("\rCopying %s %3d%%", p.name, pc)
+		p.last = pc
+	}
+	return len(b), nil
+}
+
+// hashFile returns the SHA-256 hash of the content of the file.
+func hashFile(file string) ([]byte, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// _logLevelQuiet is above every level logged, so that nothing is logged.
+const _logLevelQuiet = slog.LevelError + 1
+
+// setupLogging makes the default logger write to the log file, or to
+// standard error if there is none. Errors and information are logged with
+// -v and debug details as well with -vv. Without either only a log file gets
+// the errors and information, standard error is kept quiet.
+// The returned function closes the log file.
+func setupLogging(verbose, veryVerbose bool, logFile string) (func(), error) {
+	level := _logLevelQuiet
+	if verbose || logFile != "" {
+		level = slog.LevelInfo
+	}
+	if veryVerbose {
+		level = slog.LevelDebug
+	}
+
+	var w io.Writer = os.Stderr
+	closeLog := func() {}
+	if logFile != "" {
+		f, err := os.OpenFile(logFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		w = f
+		closeLog = func() { f.Close() }
+	}
+
+	slog.SetDefault(slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level})))
+	return closeLog, nil
+}
+
+// fail shows the message msg to the user and returns err with msg added as
+// context, so that the error logged on exit tells what failed.
+func fail(msg string, err error) error {
+	fmt.Println(msg)
+	return fmt.Errorf("%s: %w", msg, err)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// name of the sound selecting a random sound of the library, or of a
+// category when prefixed with the category
+const _randomSoundName = "random"
+
+var _random = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+var (
+	errAmbiguousSound = errors.New("Sound name matches more than one sound")
+)
+
+// resolveSound returns the name of the sound in the library selected by name.
+// An exact match is preferred, otherwise the name is matched ignoring case,
+// against the full name and against the name without its category. When
+// nothing matches the closest names are suggested.
+func (cmd *Cmd) resolveSound(name string) (string, error) {
+	if _, ok := cmd.sounds[name]; ok {
+		return name, nil
+	}
+
+	matches := cmd.matchSounds(func(sound string) bool {
+		return strings.EqualFold(sound, name)
+	})
+	if len(matches) == 0 {
+		matches = cmd.matchSounds(func(sound string) bool {
+			return strings.EqualFold(path.Base(sound), name)
+		})
+	}
+
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		fmt.Printf("Sound %s not found", name)
+		if suggestions := cmd.suggestSounds(name); len(suggestions) > 0 {
+			fmt.Printf(", did you mean %s?", strings.Join(suggestions, " or "))
+		}
+		fmt.Println()
+		return "", errSoundNotFound
+	}
+
+	fmt.Printf("Sound %s matches more than one sound: %s\n", name, strings.Join(matches, ", "))
+	return "", errAmbiguousSound
+}
+
+// isRandomSound reports whether name selects a random sound, that is if it
+// is random or category/random and no sound of the library has that name.
+func (cmd *Cmd) isRandomSound(name string) bool {
+	if _, ok := cmd.sounds[name]; ok {
+		return false
+	}
+	return strings.EqualFold(path.Base(name), _randomSoundName)
+}
+
+// randomSound returns the name of a random sound of the library. If name is
+// category/random the sound is picked from that category.
+func (cmd *Cmd) randomSound(name string) (string, error) {
+	category := path.Dir(name)
+	matches := cmd.matchSounds(func(sound string) bool {
+		return category == "." || strings.HasPrefix(strings.ToLower(sound), strings.ToLower(category)+"/")
+	})
+
+	if len(matches) == 0 {
+		if category == "." {
+			fmt.Println("No sounds in the library to pick from")
+		} else {
+			fmt.Printf("No sounds in the category %s to pick from\n", category)
+		}
+		return "", errSoundNotFound
+	}
+	return matches[_random.Intn(len(matches))], nil
+}
+
+// matchSounds returns the sorted names of the sounds for which match returns true.
+func (cmd *Cmd) matchSounds(match func(sound string) bool) []string {
+	var matches []string
+	for sound := range cmd.sounds {
+		if match(sound) {
+			matches = append(matches, sound)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// suggestSounds returns the sorted names of the sounds closest to name, if
+// they are close enough to be a likely typo.
+func (cmd *Cmd) suggestSounds(name string) []string {
+	name = strings.ToLower(name)
+	maxDist := len(name) / 3
+	if maxDist < 2 {
+		maxDist = 2
+	}
+
+	best := maxDist + 1
+	var suggestions []string
+	for sound := range cmd.sounds {
+		d := editDistance(name, strings.ToLower(sound))
+		if base := editDistance(name, strings.ToLower(path.Base(sound))); base < d {
+			d = base
+		}
+		if d < best {
+			best = d
+			suggestions = nil
+		}
+		if d == best {
+			suggestions = append(suggestions, sound)
+		}
+	}
+
+	sort.Strings(suggestions)
+	return suggestions
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// min3 returns the smallest of a, b and c.
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"rooster", "rooster", 0},
+		{"roostr", "rooster", 1},
+		{"kitten", "sitting", 3},
+		{"", "bell", 4},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s, %s: want %d got %d", tt.a, tt.b, tt.want, got)
+		}
+	}
+}
+
+func TestResolveSound(t *testing.T) {
+	cmd := &Cmd{sounds: map[string]string{
+		"Alien":       "Alien.wav",
+		"Rooster":     "Rooster.wav",
+		"nature/Rain": "nature/Rain.ogg",
+		"chimes/Bell": "chimes/Bell.wav",
+		"alarms/Bell": "alarms/Bell.wav",
+	}}
+
+	tests := []struct {
+		in, want string
+		err      error
+	}{
+		{"Alien", "Alien", nil},
+		{"alien", "Alien", nil},
+		{"rain", "nature/Rain", nil},
+		{"NATURE/rain", "nature/Rain", nil},
+		{"bell", "", errAmbiguousSound},
+		{"roostr", "", errSoundNotFound},
+	}
+
+	for _, tt := range tests {
+		got, err := cmd.resolveSound(tt.in)
+		if got != tt.want || err != tt.err {
+			t.Errorf("%s: want %s, %v got %s, %v", tt.in, tt.want, tt.err, got, err)
+		}
+	}
+}
+
+func TestSuggestSounds(t *testing.T) {
+	cmd := &Cmd{sounds: map[string]string{
+		"Alien":   "Alien.wav",
+		"Rooster": "Rooster.wav",
+	}}
+
+	if got, want := cmd.suggestSounds("roostr"), []string{"Rooster"}; !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v got %v", want, got)
+	}
+	if got := cmd.suggestSounds("thunder"); len(got) != 0 {
+		t.Errorf("want no suggestions got %v", got)
+	}
+}
+
+func TestRandomSound(t *testing.T) {
+	cmd := &Cmd{sounds: map[string]string{
+		"Alien":       "Alien.wav",
+		"nature/Rain": "nature/Rain.ogg",
+		"nature/Wind": "nature/Wind.ogg",
+	}}
+
+	for i := 0; i < 10; i++ {
+		got, err := cmd.randomSound("Nature/random")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "nature/Rain" && got != "nature/Wind" {
+			t.Errorf("want a sound of nature got %s", got)
+		}
+	}
+
+	if _, err := cmd.randomSound("chimes/random"); err != errSoundNotFound {
+		t.Errorf("want %v got %v", errSoundNotFound, err)
+	}
+	if !cmd.isRandomSound("random") || cmd.isRandomSound("Alien") {
+		t.Error("random sound not recognized")
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// _configMigrations are the settings renamed by each version of the config
+// file, from their old name to their new one. The entry at index i upgrades
+// a file of version i+1 to version i+2. A file without a version setting is
+// of version 1.
+var _configMigrations []map[string]string
+
+// configVersion returns the version of the config file read by this timer.
+func configVersion() int {
+	return len(_configMigrations) + 1
+}
+
+// migrateConfig upgrades the entries of a config file of an older version to
+// the current one. The file is then rewritten, the old one is kept next to it
+// with the .bak extension.
+func migrateConfig(data string, entries []configEntry) ([]configEntry, error) {
+	migrated, entries, changed, err := migrateEntries(data, entries)
+	if err != nil || !changed {
+		return entries, err
+	}
+
+	file := getConfigFile()
+	if err := ioutil.WriteFile(file+".bak", []byte(data), 0644); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(file, []byte(migrated), 0644); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(os.Stderr, "Config file upgraded to version %d, the previous one is %s.bak\n", configVersion(), file)
+	return entries, nil
+}
+
+// migrateEntries renames the settings of the config file data with the
+// entries read from it as far as the current version. It returns the new
+// content of the file and its entries, and whether they changed.
+func migrateEntries(data string, entries []configEntry) (string, []configEntry, bool, error) {
+	version, at := 1, -1
+	for i, e := range entries {
+		if e.section != "" || e.key != "version" {
+			continue
+		}
+		v, err := strconv.Atoi(e.value)
+		if err != nil || v < 1 {
+			return "", nil, false, fmt.Errorf("%s: version must be a positive number", e.where())
+		}
+		version, at = v, i
+	}
+	current := configVersion()
+	if version > current {
+		return "", nil, false, fmt.Errorf("%s: the config file is of version %d, this timer reads up to version %d, upgrade timer",
+			entries[at].where(), version, current)
+	}
+	if version == current {
+		return data, entries, false, nil
+	}
+
+	entries = append([]configEntry(nil), entries...)
+	lines := strings.Split(data, "\n")
+	for v := version; v < current; v++ {
+		for i, e := range entries {
+			to, ok := _configMigrations[v-1][e.key]
+			if !ok {
+				continue
+			}
+			// The key is the first word of its line
+			line := lines[e.line-1]
+			k := strings.Index(line, e.key)
+			lines[e.line-1] = line[:k] + to + line[k+len(e.key):]
+			entries[i].key = to
+		}
+	}
+
+	value := strconv.Itoa(current)
+	if at >= 0 {
+		lines[entries[at].line-1] = "version = " + value
+		entries[at].value = value
+	} else {
+		// The settings at the top of the file are before any section
+		lines = append([]string{"version = " + value}, lines...)
+		for i := range entries {
+			entries[i].line++
+		}
+		entries = append([]configEntry{{key: "version", value: value, line: 1}}, entries...)
+	}
+	return strings.Join(lines, "\n"), entries, true, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMigrateEntries(t *testing.T) {
+	defer func(m []map[string]string) { _configMigrations = m }(_configMigrations)
+	_configMigrations = []map[string]string{
+		{"sound": "default_sound"},
+		{"unit": "default_unit"},
+	}
+
+	data := `# timer config
+sound = Rooster
+  unit = s  # seconds
+
+[profile.work]
+sound = Chime
+`
+	want := `version = 3
+# timer config
+default_sound = Rooster
+  default_unit = s  # seconds
+
+[profile.work]
+default_sound = Chime
+`
+	entries, err := parseConfig(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, entries, changed, err := migrateEntries(data, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed || got != want {
+		t.Errorf("want %q got %q", want, got)
+	}
+	wantEntries, _ := parseConfig(want)
+	if !reflect.DeepEqual(wantEntries, entries) {
+		t.Errorf("want %+v got %+v", wantEntries, entries)
+	}
+
+	// A file of the current version is left as is
+	if _, _, changed, err := migrateEntries(want, wantEntries); changed || err != nil {
+		t.Errorf("version 3: want unchanged got %v %v", changed, err)
+	}
+
+	data = "version = 2\nunit = s\n"
+	entries, _ = parseConfig(data)
+	if got, _, _, err := migrateEntries(data, entries); err != nil || got != "version = 3\ndefault_unit = s\n" {
+		t.Errorf("version 2: got %q %v", got, err)
+	}
+
+	for _, data := range []string{"version = 4", "version = new"} {
+		entries, _ := parseConfig(data)
+		if _, _, _, err := migrateEntries(data, entries); err == nil {
+			t.Errorf("%q: expected error", data)
+		}
+	}
+}
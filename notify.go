@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+var (
+	errUnknownNotifier = withExitCode(_exitInvalidArgs, errors.New("Unknown notification service, see -notify-via in the help"))
+	errNoPushover      = withExitCode(_exitInvalidArgs, errors.New("Set pushover_token and pushover_user in the config file to send via pushover"))
+	errNoPushbullet    = withExitCode(_exitInvalidArgs, errors.New("Set pushbullet_token in the config file to send via pushbullet"))
+)
+
+// servers of the push services, replaced in tests
+var (
+	_pushoverAPI   = "https://api.pushover.net/1/messages.json"
+	_pushbulletAPI = "https://api.pushbullet.com/v2/pushes"
+)
+
+// notifier sends the notification of the end of a timer to a service.
+type notifier interface {
+	send(e timerEvent) error
+}
+
+// notifierFunc is a notifier sending with a function.
+type notifierFunc func(e timerEvent) error
+
+func (f notifierFunc) send(e timerEvent) error { return f(e) }
+
+// _notifiers are the services notifications can be sent to, by the name
+// given with -notify-via. Each returns the notifier configured by the flags
+// and the config file, or an error telling what is missing.
+var _notifiers = map[string]func(cmd *Cmd) (notifier, error){
+	"desktop": func(cmd *Cmd) (notifier, error) {
+		return notifierFunc(func(e timerEvent) error {
+			return showNotification(e.title(), e.body())
+		}), nil
+	},
+	"bell": func(cmd *Cmd) (notifier, error) {
+		return notifierFunc(func(e timerEvent) error {
+			_, err := fmt.Print("\a")
+			return err
+		}), nil
+	},
+	"pushover": func(cmd *Cmd) (notifier, error) {
+		token, user := cmd.config.pushoverToken, cmd.config.pushoverUser
+		if token == "" || user == "" {
+			return nil, errNoPushover
+		}
+		return notifierFunc(func(e timerEvent) error {
+			return sendPushover(token, user, e.title(), e.body())
+		}), nil
+	},
+	"pushbullet": func(cmd *Cmd) (notifier, error) {
+		token := cmd.config.pushbulletToken
+		if token == "" {
+			return nil, errNoPushbullet
+		}
+		return notifierFunc(func(e timerEvent) error {
+			return sendPushbullet(token, e.title(), e.body())
+		}), nil
+	},
+	"webhook": func(cmd *Cmd) (notifier, error) {
+		if cmd.args.webhook == "" {
+			return nil, errors.New("Give -webhook URL to send via webhook")
+		}
+		return notifierFunc(func(e timerEvent) error {
+			return sendWebhook(cmd.args.webhook, cmd.webhookTemplate(), e)
+		}), nil
+	},
+	"ntfy": func(cmd *Cmd) (notifier, error) {
+		if cmd.args.ntfy == "" {
+			return nil, errors.New("Give -ntfy TOPIC to send via ntfy")
+		}
+		return notifierFunc(func(e timerEvent) error {
+			return sendNtfy(cmd.ntfyURL(), cmd.ntfyPriority(), cmd.ntfyTags(), e)
+		}), nil
+	},
+	"slack": func(cmd *Cmd) (notifier, error) {
+		url := cmd.slackWebhook()
+		if url == "" {
+			return nil, errors.New("Give -slack-webhook URL to send via slack")
+		}
+		return notifierFunc(func(e timerEvent) error { return sendSlack(url, e) }), nil
+	},
+	"discord": func(cmd *Cmd) (notifier, error) {
+		url := cmd.discord().webhook
+		if url == "" {
+			return nil, errors.New("Give -discord-webhook URL to send via discord")
+		}
+		return notifierFunc(func(e timerEvent) error {
+			return sendDiscord(url, ":alarm_clock: "+e.text())
+		}), nil
+	},
+	"telegram": func(cmd *Cmd) (notifier, error) {
+		if !cmd.telegram() {
+			return nil, errors.New("Set telegram_token and telegram_chat_id in the config file to send via telegram")
+		}
+		token, chat := cmd.config.telegramToken, cmd.config.telegramChat
+		return notifierFunc(func(e timerEvent) error { return sendTelegram(token, chat, "⏰ "+e.text()) }), nil
+	},
+	"email": func(cmd *Cmd) (notifier, error) {
+		if cmd.args.email == "" {
+			return nil, errors.New("Give -email ADDRESSES to send via email")
+		}
+		if cmd.config.smtp.host == "" {
+			return nil, errNoSMTPServer
+		}
+		return notifierFunc(func(e timerEvent) error { return sendEmail(cmd.config.smtp, cmd.args.email, e) }), nil
+	},
+}
+
+// _hookFlags are the notifiers enabled for every timer by their own flags
+// or settings, checked in this order.
+var _hookFlags = []string{"webhook", "ntfy", "slack", "discord", "telegram", "email"}
+
+// notifierNames returns the names of the notification services, sorted.
+func notifierNames() []string {
+	names := make([]string, 0, len(_notifiers))
+	for name := range _notifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// notifyVia returns the services the notifications are sent to, given with
+// -notify-via as a comma separated list, the desktop unless given.
+func (cmd *Cmd) notifyVia() []string {
+	if cmd.args.notifyVia == "" {
+		return []string{"desktop"}
+	}
+	via := strings.Split(cmd.args.notifyVia, ",")
+	for i := range via {
+		via[i] = strings.TrimSpace(via[i])
+	}
+	return via
+}
+
+// notifiers returns the notifiers of the services of -notify-via, or an
+// error if one is not known or not configured.
+func (cmd *Cmd) notifiers() ([]notifier, error) {
+	var notifiers []notifier
+	for _, via := range cmd.notifyVia() {
+		newNotifier, ok := _notifiers[via]
+		if !ok {
+			return nil, errUnknownNotifier
+		}
+		n, err := newNotifier(cmd)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, n)
+	}
+	return notifiers, nil
+}
+
+// sendNotification sends the notification of the event to each service of
+// -notify-via. All of them are tried, the first failure is returned.
+func (cmd *Cmd) sendNotification(e timerEvent) error {
+	if cmd.silenced {
+		slog.Info("notification silenced by the script")
+		return nil
+	}
+	notifiers, err := cmd.notifiers()
+	if err != nil {
+		return err
+	}
+	var first error
+	for _, n := range notifiers {
+		if err := n.send(e); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// sendPushover pushes the notification to the devices of the Pushover user
+// with the application token.
+func sendPushover(token, user, title, message string) error {
+	form := url.Values{"token": {token}, "user": {user}, "title": {title}, "message": {message}}
+	return postHook(_pushoverAPI, http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+		[]byte(form.Encode()))
+}
+
+// sendPushbullet pushes the notification as a note to the devices of the
+// Pushbullet account of the access token.
+func sendPushbullet(token, title, message string) error {
+	body, err := json.Marshal(struct {
+		Type  string `json:"type"`
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}{"note", title, message})
+	if err != nil {
+		return err
+	}
+	return postHook(_pushbulletAPI, http.Header{"Content-Type": {"application/json"}, "Access-Token": {token}}, body)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestNotifiers(t *testing.T) {
+	cmd := &Cmd{config: &config{pushoverToken: "app", pushoverUser: "me"}}
+	tests := []struct {
+		via  string
+		n    int
+		want error
+	}{
+		{"", 1, nil},
+		{"desktop, pushover,bell", 3, nil},
+		{"pushbullet", 0, errNoPushbullet},
+		{"pager", 0, errUnknownNotifier},
+	}
+	for _, test := range tests {
+		cmd.args.notifyVia = test.via
+		notifiers, err := cmd.notifiers()
+		if err != test.want || len(notifiers) != test.n {
+			t.Errorf("notifiers(%q) = %d, %v, want %d, %v", test.via, len(notifiers), err, test.n, test.want)
+		}
+	}
+	cmd.args.notifyVia = "webhook"
+	if _, err := cmd.notifiers(); err == nil {
+		t.Error("notifiers(webhook) without -webhook did not fail")
+	}
+	cmd.args.webhook = "http://localhost/hook"
+	if _, err := cmd.notifiers(); err != nil {
+		t.Errorf("notifiers(webhook) failed: %v", err)
+	}
+}
+
+func TestPushServices(t *testing.T) {
+	var body, token string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body, token = string(data), r.Header.Get("Access-Token")
+	}))
+	defer server.Close()
+	defer func(pushover, pushbullet string) {
+		_pushoverAPI, _pushbulletAPI = pushover, pushbullet
+	}(_pushoverAPI, _pushbulletAPI)
+	_pushoverAPI, _pushbulletAPI = server.URL, server.URL
+
+	cmd := &Cmd{config: &config{pushoverToken: "app", pushoverUser: "me", pushbulletToken: "secret"}}
+	cmd.args.notifyVia = "pushover"
+	e := timerEvent{Message: "Tea is ready"}
+	if err := cmd.sendNotification(e); err != nil {
+		t.Fatalf("sending via pushover failed: %v", err)
+	}
+	if want := "message=Tea+is+ready&title=" + url.QueryEscape(tr(_msgNotifyTitle)) + "&token=app&user=me"; body != want {
+		t.Errorf("pushover got %s, want %s", body, want)
+	}
+
+	cmd.args.notifyVia = "pushbullet"
+	if err := cmd.sendNotification(e); err != nil {
+		t.Fatalf("sending via pushbullet failed: %v", err)
+	}
+	if want := `{"type":"note","title":"` + tr(_msgNotifyTitle) + `","body":"Tea is ready"}`; body != want || token != "secret" {
+		t.Errorf("pushbullet got %s with token %q, want %s", body, token, want)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+var (
+	errInvalidPriority = withExitCode(_exitInvalidArgs, errors.New("Priority must be min, low, default, high, max or 1 to 5"))
+)
+
+const (
+	// server the ntfy topics are published to, unless configured
+	_ntfyServer = "https://ntfy.sh"
+	// tags of the ntfy messages unless configured, shown as emojis
+	_ntfyTags = "alarm_clock"
+)
+
+// ntfyURL returns the URL the ntfy topic given with -ntfy is published to.
+// The topic may be a URL of a topic on any server.
+func (cmd *Cmd) ntfyURL() string {
+	if isURL(cmd.args.ntfy) {
+		return cmd.args.ntfy
+	}
+	server := _ntfyServer
+	if cmd.config.ntfyServer != "" {
+		server = cmd.config.ntfyServer
+	}
+	return strings.TrimSuffix(server, "/") + "/" + cmd.args.ntfy
+}
+
+// ntfyPriority returns the priority of the ntfy messages given with
+// -ntfy-priority or in the config file, if any.
+func (cmd *Cmd) ntfyPriority() string {
+	if cmd.args.ntfyPriority != "" {
+		return cmd.args.ntfyPriority
+	}
+	return cmd.config.ntfyPriority
+}
+
+// ntfyTags returns the tags of the ntfy messages given with -ntfy-tags or in
+// the config file.
+func (cmd *Cmd) ntfyTags() string {
+	if cmd.args.ntfyTags != "" {
+		return cmd.args.ntfyTags
+	}
+	if cmd.config.ntfyTags != "" {
+		return cmd.config.ntfyTags
+	}
+	return _ntfyTags
+}
+
+// checkPriority returns an error if the ntfy priority is not one ntfy knows.
+// An empty priority is the default one.
+func checkPriority(priority string) error {
+	switch priority {
+	case "", "min", "low", "default", "high", "max", "urgent", "1", "2", "3", "4", "5":
+		return nil
+	}
+	return errInvalidPriority
+}
+
+// sendNtfy publishes the message telling how the timer ended to the ntfy
+// topic at the URL, with the priority and the comma separated tags.
+func sendNtfy(url, priority, tags string, e timerEvent) error {
+	header := http.Header{
+		"Content-Type": {"text/plain; charset=utf-8"},
+		// Non-ASCII header values are encoded as ntfy expects them
+		"Title": {mime.QEncoding.Encode("utf-8", e.title())},
+		"Tags":  {tags},
+	}
+	if priority != "" {
+		header.Set("Priority", priority)
+	}
+	return postHook(url, header, []byte(e.body()))
+}
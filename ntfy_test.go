@@ -0,0 +1,58 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNtfyURL(t *testing.T) {
+	cmd := &Cmd{config: &config{}}
+	cmd.args.ntfy = "kitchen"
+	if got := cmd.ntfyURL(); got != "https://ntfy.sh/kitchen" {
+		t.Errorf("ntfyURL() = %s, want https://ntfy.sh/kitchen", got)
+	}
+	cmd.config.ntfyServer = "https://ntfy.example.com/"
+	if got := cmd.ntfyURL(); got != "https://ntfy.example.com/kitchen" {
+		t.Errorf("ntfyURL() = %s with a server, want https://ntfy.example.com/kitchen", got)
+	}
+	cmd.args.ntfy = "http://localhost:8080/oven"
+	if got := cmd.ntfyURL(); got != cmd.args.ntfy {
+		t.Errorf("ntfyURL() = %s, want the topic URL %s", got, cmd.args.ntfy)
+	}
+}
+
+func TestSendNtfy(t *testing.T) {
+	var header http.Header
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		header, body = r.Header, string(data)
+	}))
+	defer server.Close()
+
+	e := timerEvent{Label: "Tea", Status: _statusExpired}
+	if err := sendNtfy(server.URL+"/kitchen", "high", "tea,alarm_clock", e); err != nil {
+		t.Fatalf("sendNtfy failed: %v", err)
+	}
+	if body != tr(_msgNotifyText) {
+		t.Errorf("ntfy message = %q", body)
+	}
+	if header.Get("Priority") != "high" || header.Get("Tags") != "tea,alarm_clock" || header.Get("Title") != tr(_msgNotifyTitle)+": Tea" {
+		t.Errorf("ntfy headers = %v", header)
+	}
+}
+
+func TestCheckPriority(t *testing.T) {
+	for _, p := range []string{"", "min", "high", "5"} {
+		if err := checkPriority(p); err != nil {
+			t.Errorf("checkPriority(%q) failed: %v", p, err)
+		}
+	}
+	for _, p := range []string{"loud", "6", "0"} {
+		if err := checkPriority(p); err != errInvalidPriority {
+			t.Errorf("checkPriority(%q) = %v, want %v", p, err, errInvalidPriority)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+var (
+	errUnknownOrgFormat = errors.New("org_format must be clock or list")
+)
+
+// layout of the timestamps of org-mode
+const _orgTimestamp = "[2006-01-02 Mon 15:04]"
+
+// appendOrg appends the timer started at start and expired at end to the
+// org_file of the config file, as a heading with a CLOCK line or with
+// org_format list as an item of a list.
+func (cmd *Cmd) appendOrg(start, end time.Time) error {
+	f, err := os.OpenFile(expandPath(cmd.config.orgFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(orgEntry(cmd.config.orgFormat, cmd.label, start, end)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// orgEntry returns the lines of the timer labelled label started at start
+// and expired at end in the org format, clock or list.
+func orgEntry(format, label string, start, end time.Time) string {
+	d := end.Sub(start).Round(time.Minute)
+	if format == "list" {
+		text := fmt.Sprintf("%v", end.Sub(start).Round(time.Second))
+		if label != "" {
+			text = label + ", " + text
+		}
+		return fmt.Sprintf("- %s %s\n", start.Format(_orgTimestamp), text)
+	}
+
+	if label == "" {
+		label = "Timer"
+	}
+	return fmt.Sprintf("* %s\n  CLOCK: %s--%s => %2d:%02d\n", label, start.Format(_orgTimestamp),
+		end.Format(_orgTimestamp), int(d.Hours()), int(d.Minutes())%60)
+}
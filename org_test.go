@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOrgEntry(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		format, label string
+		d             time.Duration
+		want          string
+	}{
+		{"clock", "Tea", 4 * time.Minute, "* Tea\n  CLOCK: [2024-03-01 Fri 09:00]--[2024-03-01 Fri 09:04] =>  0:04\n"},
+		{"", "", 90 * time.Minute, "* Timer\n  CLOCK: [2024-03-01 Fri 09:00]--[2024-03-01 Fri 10:30] =>  1:30\n"},
+		{"list", "Tea", 4 * time.Minute, "- [2024-03-01 Fri 09:00] Tea, 4m0s\n"},
+		{"list", "", 30 * time.Second, "- [2024-03-01 Fri 09:00] 30s\n"},
+	}
+
+	for _, test := range tests {
+		if got := orgEntry(test.format, test.label, start, start.Add(test.d)); got != test.want {
+			t.Errorf("%s %q: want %q got %q", test.format, test.label, test.want, got)
+		}
+	}
+}
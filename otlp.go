@@ -0,0 +1,176 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// otlpEndpoint returns the URL of the OpenTelemetry collector given with
+// -otlp, in the config file or in OTEL_EXPORTER_OTLP_ENDPOINT, if any.
+func (cmd *Cmd) otlpEndpoint() string {
+	if cmd.args.otlp != "" {
+		return cmd.args.otlp
+	}
+	if cmd.config.otlpEndpoint != "" {
+		return cmd.config.otlpEndpoint
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+}
+
+// otlpHeader returns the header of the requests to the collector, with the
+// comma separated key=value pairs of otlp_headers or of
+// OTEL_EXPORTER_OTLP_HEADERS.
+func (cmd *Cmd) otlpHeader() http.Header {
+	header := http.Header{"Content-Type": {"application/json"}}
+	pairs := cmd.config.otlpHeaders
+	if pairs == "" {
+		pairs = os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")
+	}
+	for _, pair := range strings.Split(pairs, ",") {
+		if key, value, ok := strings.Cut(pair, "="); ok {
+			header.Set(strings.TrimSpace(key), strings.TrimSpace(value))
+		}
+	}
+	return header
+}
+
+// exportOTLP sends the event of the timer started at start and expiring at
+// end to the collector as a log record, in the background. The end of the
+// timer is sent as a span from its start as well.
+func (cmd *Cmd) exportOTLP(event string, start, end time.Time) {
+	url := cmd.otlpEndpoint()
+	if url == "" {
+		return
+	}
+	url = strings.TrimSuffix(url, "/")
+	header, now := cmd.otlpHeader(), time.Now()
+	attrs := otlpAttributes(event, cmd.label, start, end)
+	cmd.sendHook("otlp", func() error {
+		body, err := otlpLogs(event, now, attrs)
+		if err != nil {
+			return err
+		}
+		return postHook(url+"/v1/logs", header, body)
+	})
+	if event == _statusExpired || event == _statusCancelled {
+		cmd.sendHook("otlp", func() error {
+			body, err := otlpTraces(event, start, now, attrs)
+			if err != nil {
+				return err
+			}
+			return postHook(url+"/v1/traces", header, body)
+		})
+	}
+}
+
+// otlpValue is an attribute value of OTLP/JSON.
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// otlpAttribute is an attribute of a resource, a log record or a span.
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+func otlpString(key, value string) otlpAttribute {
+	return otlpAttribute{key, otlpValue{StringValue: &value}}
+}
+
+func otlpDouble(key string, value float64) otlpAttribute {
+	return otlpAttribute{key, otlpValue{DoubleValue: &value}}
+}
+
+// otlpAttributes returns the attributes of the event of the timer.
+func otlpAttributes(event, label string, start, end time.Time) []otlpAttribute {
+	attrs := []otlpAttribute{
+		otlpString("timer.event", event),
+		otlpDouble("timer.duration", end.Sub(start).Seconds()),
+		otlpString("timer.started_at", start.Format(time.RFC3339)),
+		otlpString("timer.ends_at", end.Format(time.RFC3339)),
+	}
+	if label != "" {
+		attrs = append(attrs, otlpString("timer.label", label))
+	}
+	return attrs
+}
+
+// otlpResource is the resource of every log record and span, the service
+// timer on this host.
+func otlpResource() map[string]interface{} {
+	attrs := []otlpAttribute{otlpString("service.name", "timer")}
+	if host, err := os.Hostname(); err == nil {
+		attrs = append(attrs, otlpString("host.name", host))
+	}
+	return map[string]interface{}{"attributes": attrs}
+}
+
+// otlpNano returns the time as a string of nanoseconds since the epoch, as
+// OTLP/JSON expects 64 bit integers.
+func otlpNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// otlpLogs returns the OTLP/JSON export request of the log record of the
+// event at t.
+func otlpLogs(event string, t time.Time, attrs []otlpAttribute) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"resourceLogs": []interface{}{map[string]interface{}{
+			"resource": otlpResource(),
+			"scopeLogs": []interface{}{map[string]interface{}{
+				"scope": map[string]string{"name": "timer"},
+				"logRecords": []interface{}{map[string]interface{}{
+					"timeUnixNano":   otlpNano(t),
+					"severityNumber": 9,
+					"severityText":   "INFO",
+					"body":           otlpValue{StringValue: &event},
+					"attributes":     attrs,
+				}},
+			}},
+		}},
+	})
+}
+
+// otlpTraces returns the OTLP/JSON export request of the span of the timer
+// from its start until it ended at t with the event.
+func otlpTraces(event string, start, t time.Time, attrs []otlpAttribute) ([]byte, error) {
+	trace, span := make([]byte, 16), make([]byte, 8)
+	if _, err := rand.Read(trace); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(span); err != nil {
+		return nil, err
+	}
+	// Status codes of spans, ok for an expired timer and error for an
+	// interrupted one
+	code := 1
+	if event == _statusCancelled {
+		code = 2
+	}
+	return json.Marshal(map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": otlpResource(),
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": map[string]string{"name": "timer"},
+				"spans": []interface{}{map[string]interface{}{
+					"traceId":           hex.EncodeToString(trace),
+					"spanId":            hex.EncodeToString(span),
+					"name":              "timer",
+					"kind":              1,
+					"startTimeUnixNano": otlpNano(start),
+					"endTimeUnixNano":   otlpNano(t),
+					"attributes":        attrs,
+					"status":            map[string]int{"code": code},
+				}},
+			}},
+		}},
+	})
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExportOTLP(t *testing.T) {
+	var mu sync.Mutex
+	got := make(map[string]map[string]interface{})
+	var key string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]interface{}
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("%s: %v", r.URL.Path, err)
+		}
+		mu.Lock()
+		got[r.URL.Path], key = body, r.Header.Get("Api-Key")
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	cmd := &Cmd{args: cmdArgs{otlp: server.URL + "/"}, config: &config{otlpHeaders: "api-key = secret"}, label: "Incident"}
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	cmd.exportOTLP(_statusCancelled, start, start.Add(time.Hour))
+	cmd.hooks.Wait()
+
+	if key != "secret" {
+		t.Errorf("want the header api-key secret got %q", key)
+	}
+	record := got["/v1/logs"]["resourceLogs"].([]interface{})[0].(map[string]interface{})["scopeLogs"].([]interface{})[0].(map[string]interface{})["logRecords"].([]interface{})[0].(map[string]interface{})
+	if body := record["body"].(map[string]interface{})["stringValue"]; body != _statusCancelled {
+		t.Errorf("want the log body %s got %v", _statusCancelled, body)
+	}
+	attrs := make(map[string]interface{})
+	for _, a := range record["attributes"].([]interface{}) {
+		a := a.(map[string]interface{})
+		for _, v := range a["value"].(map[string]interface{}) {
+			attrs[a["key"].(string)] = v
+		}
+	}
+	if attrs["timer.label"] != "Incident" || attrs["timer.duration"] != 3600.0 {
+		t.Errorf("unexpected attributes %v", attrs)
+	}
+
+	span := got["/v1/traces"]["resourceSpans"].([]interface{})[0].(map[string]interface{})["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})[0].(map[string]interface{})
+	if span["startTimeUnixNano"] != "1709283600000000000" || len(span["traceId"].(string)) != 32 {
+		t.Errorf("unexpected span %v", span)
+	}
+	if code := span["status"].(map[string]interface{})["code"]; code != 2.0 {
+		t.Errorf("want the error status of a cancelled timer got %v", code)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// countOvertime processes -overtime.
+// Count up the time passed since the timer expired until a key is pressed or
+// the command is interrupted, then record the timer and its overtime in the
+// history. It returns true if the key pressed was r, to restart the timer.
+func (cmd *Cmd) countOvertime() (bool, error) {
+	keys, restore := terminalKeys()
+	defer restore()
+
+	fmt.Println("Press any key to stop counting the overtime, r to restart the timer")
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	restart := false
+	cmd.showOvertime(time.Since(cmd.expired))
+	for done := false; !done; {
+		select {
+		case <-ticker.C:
+			cmd.showOvertime(time.Since(cmd.expired))
+		case key, ok := <-keys:
+			if !ok {
+				// Without standard input only an interrupt stops counting
+				keys = nil
+				continue
+			}
+			done, restart = true, key == "r"
+		case <-cmd.ctx.Done():
+			done = true
+		}
+	}
+
+	over := time.Since(cmd.expired)
+	fmt.Printf("\nOvertime of %s\n", formatClock(over))
+	if err := appendHistory(historyEntry{
+		Kind:     _historyTimer,
+		Label:    cmd.label,
+		Start:    cmd.started,
+		Duration: cmd.expired.Sub(cmd.started).Seconds(),
+		Overtime: over.Seconds(),
+	}); err != nil {
+		return false, fail("Error recording the timer in the history", err)
+	}
+	return restart, nil
+}
+
+// showOvertime shows the overtime on the current line.
+func (cmd *Cmd) showOvertime(over time.Duration) {
+	line := fmt.Sprintf("+%s over", formatClock(over))
+	if cmd.ansi {
+		fmt.Printf("\r\x1b[K⏱  %s", line)
+		return
+	}
+	fmt.Printf("\r%-79s", line)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	errNoPackIndex    = errors.New("No sound pack index configured, set TIMER_SOUND_INDEX")
+	errPackNotFound   = errors.New("Sound pack not found in index")
+	errChecksumFailed = errors.New("Checksum of downloaded sound does not match")
+	errBadSoundName   = errors.New("Invalid sound name in sound pack")
+)
+
+// name of environment variable storing the URL of the sound pack index
+const _timerSoundIndex = "TIMER_SOUND_INDEX"
+
+// soundPackIndex is the published list of sound packs.
+type soundPackIndex struct {
+	Packs []soundPack `json:"packs"`
+}
+
+// soundPack is a named collection of sounds which can be installed at once.
+type soundPack struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Sounds      []packSound `json:"sounds"`
+}
+
+// packSound is a sound of a sound pack along with the checksum of its file.
+type packSound struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256"`
+}
+
+// loadPackIndex downloads the sound pack index.
+func (cmd *Cmd) loadPackIndex() (*soundPackIndex, error) {
+	loc := os.Getenv(_timerSoundIndex)
+	if loc == "" {
+		return nil, errNoPackIndex
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if isURL(loc) {
+		data, err = fetch(cmd.ctx, loc, nil)
+	} else {
+		data, err = ioutil.ReadFile(loc)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index := &soundPackIndex{}
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// searchPacks processes the command (sound search [TERM]).
+// List the sound packs in the index whose name or description contains TERM.
+func (cmd *Cmd) searchPacks(args []string) error {
+	if len(args) > 1 {
+		fmt.Println("Expected at most one search term")
+		return errInvalidArgs
+	}
+
+	index, err := cmd.loadPackIndex()
+	if err != nil {
+		return fail("Error reading the sound pack index", err)
+	}
+
+	term := ""
+	if len(args) == 1 {
+		term = strings.ToLower(args[0])
+	}
+	for _, p := range index.Packs {
+		if strings.Contains(strings.ToLower(p.Name+" "+p.Description), term) {
+			fmt.Printf("%-20s %s (%d sounds)\n", p.Name, p.Description, len(p.Sounds))
+		}
+	}
+
+	return nil
+}
+
+// installPack processes the command (sound install PACK).
+// Download the sounds of the sound pack into the sound library. Every file is
+// verified against its checksum from the index before it is added.
+func (cmd *Cmd) installPack(args []string) error {
+	if len(args) != 1 {
+		fmt.Println("Expected the name of the sound pack")
+		return errInvalidArgs
+	}
+
+	index, err := cmd.loadPackIndex()
+	if err != nil {
+		return fail("Error reading the sound pack index", err)
+	}
+
+	var pack *soundPack
+	for i := range index.Packs {
+		if index.Packs[i].Name == args[0] {
+			pack = &index.Packs[i]
+		}
+	}
+	if pack == nil {
+		fmt.Printf("Sound pack %s not found\n", args[0])
+		return errPackNotFound
+	}
+
+	for _, s := range pack.Sounds {
+		if !validSoundName(s.Name) {
+			fmt.Printf("Invalid sound name %q in sound pack\n", s.Name)
+			return errBadSoundName
+		}
+
+		u, err := url.Parse(s.URL)
+		if err != nil {
+			return err
+		}
+		fileLoc := cmd.soundFile(s.Name, path.Ext(u.Path))
+		if err := cmd.installPackSound(s, fileLoc); err != nil {
+			return err
+		}
+		fmt.Println("Added", s.Name)
+	}
+
+	fmt.Printf("Installed %d sounds from sound pack %s\n", len(pack.Sounds), pack.Name)
+	return nil
+}
+
+// installPackSound downloads the sound of a sound pack next to fileLoc, and
+// renames it to fileLoc once it is verified against its checksum.
+func (cmd *Cmd) installPackSound(s packSound, fileLoc string) error {
+	tmp, _, err := download(cmd.ctx, s.URL, filepath.Dir(fileLoc))
+	if err != nil {
+		fmt.Printf("Error downloading sound %s\n", s.Name)
+		return err
+	}
+	defer os.Remove(tmp)
+
+	sum, err := hashFile(tmp)
+	if err != nil {
+		fmt.Printf("Error verifying sound %s\n", s.Name)
+		return err
+	}
+	if !strings.EqualFold(hex.EncodeToString(sum), s.SHA256) {
+		fmt.Printf("Error verifying sound %s\n", s.Name)
+		return errChecksumFailed
+	}
+
+	if err := os.Rename(tmp, fileLoc); err != nil {
+		fmt.Printf("Error adding sound %s\n", s.Name)
+		return err
+	}
+	return nil
+}
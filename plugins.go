@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// time a plugin is given to handle an event before it is killed
+const _pluginTimeout = 30 * time.Second
+
+// pluginEvent is an event of a timer sent to the plugins, when it starts,
+// expires or is cancelled. The duration is in seconds.
+type pluginEvent struct {
+	Event     string    `json:"event"`
+	Label     string    `json:"label,omitempty"`
+	Duration  float64   `json:"duration"`
+	StartedAt time.Time `json:"started_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	// message of the end of the timer, from -done-msg or the templates
+	Message string `json:"message,omitempty"`
+}
+
+// getPluginsDir returns the directory of the plugins, executables which are
+// run on every event of a timer.
+func getPluginsDir() string {
+	return filepath.Join(getConfigDir(), "plugins")
+}
+
+// plugins returns the executables in the plugins directory, sorted by name.
+func plugins() ([]string, error) {
+	entries, err := os.ReadDir(getPluginsDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || !isExecutable(info) {
+			continue
+		}
+		files = append(files, filepath.Join(getPluginsDir(), entry.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// runPlugins runs the plugins in the background with the event of the timer
+// started at start and expiring at end as JSON on standard input.
+func (cmd *Cmd) runPlugins(event string, start, end time.Time) {
+	files, err := plugins()
+	if err != nil || len(files) == 0 {
+		return
+	}
+	e := pluginEvent{
+		Event:     event,
+		Label:     cmd.label,
+		Duration:  end.Sub(start).Seconds(),
+		StartedAt: start,
+		EndsAt:    end,
+	}
+	if event == _statusExpired || event == _statusCancelled {
+		e.Message = cmd.event(event, start, end).Message
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		file := file
+		cmd.sendHook("plugin "+filepath.Base(file), func() error { return runPlugin(file, data, e.env()) })
+	}
+}
+
+// env returns the environment variables of the fields of the event, like
+// TIMER_EVENT_LABEL, added to the environment of the plugins. They are not
+// named like the variables of the flags, such as TIMER_LABEL for -label, so
+// that a timer run by a plugin does not take them as its flags.
+func (e pluginEvent) env() []string {
+	return []string{
+		"TIMER_EVENT=" + e.Event,
+		"TIMER_EVENT_LABEL=" + e.Label,
+		"TIMER_EVENT_DURATION=" + strconv.FormatFloat(e.Duration, 'f', -1, 64),
+		"TIMER_EVENT_STARTED_AT=" + e.StartedAt.Format(time.RFC3339),
+		"TIMER_EVENT_ENDS_AT=" + e.EndsAt.Format(time.RFC3339),
+		"TIMER_EVENT_MESSAGE=" + e.Message,
+	}
+}
+
+// runPlugin runs the plugin with the event on standard input and the
+// variables of env added to its environment. The standard error of a failed
+// plugin is returned as part of the error.
+func runPlugin(file string, event []byte, env []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), _pluginTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	ex := exec.CommandContext(ctx, file)
+	ex.Stdin = bytes.NewReader(append(event, '\n'))
+	ex.Env = append(os.Environ(), env...)
+	ex.Stderr = &stderr
+	if err := ex.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%v: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
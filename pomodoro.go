@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	// durations of the phases of the pomodoro technique, unless configured
+	_pomodoroWork       = 25 * time.Minute
+	_pomodoroShortBreak = 5 * time.Minute
+	_pomodoroLongBreak  = 15 * time.Minute
+	// a long break follows every this many pomodoros
+	_pomodoroLongBreakEvery = 4
+	// number of weeks whose totals are shown by pomodoro stats
+	_pomodoroStatsWeeks = 4
+	// layout of the dates pomodoros are counted by
+	_dayLayout = "2006-01-02"
+)
+
+// pomodoro processes the command (pomodoro).
+// Alternate pomodoros of work with short breaks, and a long break after every
+// fourth pomodoro, until q is pressed. The durations and how often the long
+// break is are set in the config file or with flags. The next phase starts when a key is
+// pressed, or with -auto-continue after a grace period. Finished pomodoros are recorded in the history, and a notification
+// is shown when the daily goal set with -goal or pomodoro_goal is reached.
+func (cmd *Cmd) pomodoro(args []string) error {
+	if len(args) != 0 {
+		fmt.Println("Unexpected arguments to pomodoro")
+		return errInvalidArgs
+	}
+
+	sound := cmd.args.sound
+	if cmd.args.withSound && sound == "" {
+		sound = _defaultSoundName
+	}
+	if sound == "" {
+		sound = cmd.config.profileSound
+	}
+	if sound != "" {
+		var err error
+		if sound, err = cmd.selectSound(sound); err != nil {
+			return err
+		}
+	}
+	goal := cmd.config.pomodoroGoal
+	if cmd.args.goal > 0 {
+		goal = cmd.args.goal
+	}
+	work, shortBreak, longBreak, every := cmd.config.pomodoroWork, cmd.config.pomodoroShortBreak,
+		cmd.config.pomodoroLongBreak, cmd.config.pomodoroLongBreakEvery
+	if cmd.args.work > 0 {
+		work = cmd.args.work
+	}
+	if cmd.args.shortBreak > 0 {
+		shortBreak = cmd.args.shortBreak
+	}
+	if cmd.args.longBreak > 0 {
+		longBreak = cmd.args.longBreak
+	}
+	if cmd.args.longBreakEvery > 0 {
+		every = cmd.args.longBreakEvery
+	}
+
+	// The pomodoros are recorded as such, and the breaks are not timers
+	cmd.ownHistory = true
+	for session := 1; ; session++ {
+		cmd.label = fmt.Sprintf("Pomodoro %d", session)
+		fmt.Println(cmd.label)
+		start := time.Now()
+		if err := cmd.countdown(work); err != nil {
+			return err
+		}
+		if err := appendHistory(historyEntry{
+			Kind: _historyPomodoro, Start: start, Duration: work.Seconds(),
+		}); err != nil {
+			return fail("Error recording the pomodoro in the history", err)
+		}
+
+		text := "Pomodoro finished, time for a break"
+		if goal > 0 {
+			if today := cmd.pomodorosToday(); today == goal {
+				text = fmt.Sprintf("Daily goal of %d pomodoros reached!", goal)
+				fmt.Println(text)
+			}
+		}
+		if err := cmd.batchAlert(batchTimer{sound: sound}, text, true); err != nil {
+			return err
+		}
+
+		pause, label := shortBreak, "Short break"
+		if session%every == 0 {
+			pause, label = longBreak, "Long break"
+		}
+		if ok, err := cmd.nextPhase(label); !ok {
+			return err
+		}
+		cmd.label = label
+		fmt.Println(label)
+		if err := cmd.countdown(pause); err != nil {
+			return err
+		}
+		if err := cmd.batchAlert(batchTimer{sound: sound}, "Break is over, back to work", true); err != nil {
+			return err
+		}
+		if ok, err := cmd.nextPhase(fmt.Sprintf("Pomodoro %d", session+1)); !ok {
+			return err
+		}
+	}
+}
+
+// nextPhase waits for a key before the phase named label starts, or with
+// -auto-continue at most for the grace period, and returns false if the
+// pomodoros should stop instead.
+func (cmd *Cmd) nextPhase(label string) (bool, error) {
+	prompt, grace := fmt.Sprintf("Press any key to start: %s, or q to quit", label), time.Duration(0)
+	if cmd.args.autoContinue {
+		grace = cmd.args.grace
+		prompt = fmt.Sprintf("%s starts in %v, press any key to start now or q to quit", label, grace)
+	}
+
+	key, err := cmd.waitKey(prompt, grace)
+	if err != nil {
+		return false, err
+	}
+	return key != "q", nil
+}
+
+// pomodorosToday returns the number of pomodoros finished today.
+func (cmd *Cmd) pomodorosToday() int {
+	y, m, d := time.Now().Date()
+	entries, err := queryHistory(historyQuery{
+		from: time.Date(y, m, d, 0, 0, 0, 0, time.Local), kind: _historyPomodoro,
+	})
+	if err != nil {
+		slog.Warn("reading the history failed", "err", err)
+	}
+	return pomodoroCounts(entries)[time.Now().Format(_dayLayout)]
+}
+
+// pomodoroStats processes the command (pomodoro stats).
+// Show the number of pomodoros finished today, in each of the last weeks and
+// the number of days in a row with finished pomodoros.
+func (cmd *Cmd) pomodoroStats(args []string) error {
+	if len(args) != 0 {
+		fmt.Println("Unexpected arguments to pomodoro stats")
+		return errInvalidArgs
+	}
+
+	entries, err := queryHistory(historyQuery{kind: _historyPomodoro})
+	if err != nil {
+		return fail("Error reading the history", err)
+	}
+	counts := pomodoroCounts(entries)
+	now := time.Now()
+
+	goal := cmd.config.pomodoroGoal
+	if cmd.args.goal > 0 {
+		goal = cmd.args.goal
+	}
+	today := counts[now.Format(_dayLayout)]
+	if goal > 0 {
+		fmt.Printf("Today           %d of %d\n", today, goal)
+	} else {
+		fmt.Printf("Today           %d\n", today)
+	}
+	fmt.Printf("Streak          %d days\n", pomodoroStreak(counts, now))
+	for _, week := range pomodoroWeeks(counts, now, _pomodoroStatsWeeks) {
+		fmt.Printf("Week of %s  %d\n", week.start.Format(_dayLayout), week.count)
+	}
+	return nil
+}
+
+// pomodoroCounts returns the number of pomodoros finished on each day, keyed
+// by the date in local time.
+func pomodoroCounts(entries []historyEntry) map[string]int {
+	counts := make(map[string]int)
+	for _, e := range entries {
+		if e.Kind == _historyPomodoro {
+			counts[e.Start.Local().Format(_dayLayout)]++
+		}
+	}
+	return counts
+}
+
+// pomodoroStreak returns the number of days in a row with pomodoros up to
+// today, or up to yesterday if there are none yet today.
+func pomodoroStreak(counts map[string]int, now time.Time) int {
+	y, m, d := now.Date()
+	if counts[now.Format(_dayLayout)] == 0 {
+		d--
+	}
+
+	streak := 0
+	for counts[time.Date(y, m, d-streak, 0, 0, 0, 0, now.Location()).Format(_dayLayout)] > 0 {
+		streak++
+	}
+	return streak
+}
+
+// pomodoroWeek is the number of pomodoros finished in the week starting on
+// Monday start.
+type pomodoroWeek struct {
+	start time.Time
+	count int
+}
+
+// pomodoroWeeks returns the number of pomodoros of this week and of the n-1
+// weeks before it, the current one first.
+func pomodoroWeeks(counts map[string]int, now time.Time, n int) []pomodoroWeek {
+	y, m, d := now.Date()
+	// Weeks start on Monday, Sunday is the last day of the week
+	monday := d - (int(now.Weekday())+6)%7
+
+	weeks := make([]pomodoroWeek, n)
+	for i := range weeks {
+		first := monday - 7*i
+		weeks[i].start = time.Date(y, m, first, 0, 0, 0, 0, now.Location())
+		for day := first; day < first+7; day++ {
+			weeks[i].count += counts[time.Date(y, m, day, 0, 0, 0, 0, now.Location()).Format(_dayLayout)]
+		}
+	}
+	return weeks
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPomodoroStats(t *testing.T) {
+	day := func(d, h int) time.Time {
+		return time.Date(2026, 10, d, h, 0, 0, 0, time.Local)
+	}
+	var entries []historyEntry
+	// one pomodoro on Sunday the 4th, and a streak from Tuesday the 13th
+	for _, start := range []time.Time{day(4, 10), day(13, 9), day(14, 9), day(14, 23), day(15, 8)} {
+		entries = append(entries, historyEntry{Kind: _historyPomodoro, Start: start})
+	}
+	entries = append(entries, historyEntry{Kind: "timer", Start: day(12, 9)})
+	counts := pomodoroCounts(entries)
+
+	if got := counts["2026-10-14"]; got != 2 {
+		t.Errorf("want 2 pomodoros on the 14th got %d", got)
+	}
+	if got := counts["2026-10-12"]; got != 0 {
+		t.Errorf("want no pomodoros on the 12th got %d", got)
+	}
+
+	for _, tt := range []struct {
+		now  time.Time
+		want int
+	}{
+		{day(15, 20), 3},
+		// no pomodoro yet today, the streak goes on until the day is over
+		{day(16, 8), 3},
+		{day(17, 8), 0},
+		{day(12, 8), 0},
+	} {
+		if got := pomodoroStreak(counts, tt.now); got != tt.want {
+			t.Errorf("streak on %v: want %d got %d", tt.now, tt.want, got)
+		}
+	}
+
+	// The 15th is a Thursday and the 4th a Sunday, the last day of its week
+	weeks := pomodoroWeeks(counts, day(15, 20), 3)
+	want := []pomodoroWeek{{day(12, 0), 4}, {day(5, 0), 0}, {time.Date(2026, 9, 28, 0, 0, 0, 0, time.Local), 1}}
+	for i := range want {
+		if !weeks[i].start.Equal(want[i].start) || weeks[i].count != want[i].count {
+			t.Errorf("week %d: want %v, %d got %v, %d", i, want[i].start, want[i].count, weeks[i].start, weeks[i].count)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+var (
+	errInvalidRounds = errors.New("Rounds must be a positive number")
+)
+
+const (
+	// time to get ready before the first round of a workout
+	_presetPrepare = 10 * time.Second
+	// number of beeps counting down to each phase of a workout
+	_presetBeeps = 3
+	// tone played when a phase of a workout starts
+	_phaseTone = "1320hz:400ms"
+)
+
+// preset is a workout of rounds of work, each followed by a rest.
+type preset struct {
+	rounds int
+	work   time.Duration
+	rest   time.Duration
+	// label of the work phases, numbered by round
+	label string
+}
+
+// _presets are the workouts which can be run by name.
+var _presets = map[string]preset{
+	// 8 rounds of 20 seconds of work and 10 seconds of rest
+	"tabata": {rounds: 8, work: 20 * time.Second, rest: 10 * time.Second, label: "Work"},
+	// every minute on the minute, a round starts each minute
+	"emom": {rounds: 10, work: time.Minute, label: "Minute"},
+}
+
+// presetCommand returns the function processing the command (NAME [ROUNDS])
+// of the preset named name.
+func (cmd *Cmd) presetCommand(name string) func(args []string) error {
+	return func(args []string) error {
+		cmd.preset = name
+		return cmd.runPreset(_presets[name], args)
+	}
+}
+
+// runPreset runs the workout of the preset, the number of rounds may be given
+// as the argument and the durations of the phases with -work and -rest. The
+// last seconds of each phase are counted down with beeps, and a tone or the
+// sound given with -sound is played when the next phase starts.
+func (cmd *Cmd) runPreset(p preset, args []string) error {
+	if len(args) > 1 {
+		fmt.Println("Expected at most the number of rounds")
+		return errInvalidArgs
+	}
+	if len(args) == 1 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			fmt.Println("Invalid number of rounds", args[0])
+			return errInvalidRounds
+		}
+		p.rounds = n
+	}
+	if cmd.args.work > 0 {
+		p.work = cmd.args.work
+	}
+	if cmd.args.rest >= 0 {
+		p.rest = cmd.args.rest
+	}
+
+	var err error
+	if !flagGiven("beeps") {
+		cmd.args.beeps = _presetBeeps
+		if cmd.beep, err = cmd.addTone(_beepTone); err != nil {
+			return err
+		}
+	}
+
+	sound := cmd.args.sound
+	if sound == "" && !cmd.args.withSound {
+		if sound, err = cmd.addTone(_phaseTone); err != nil {
+			return err
+		}
+	}
+
+	timers := []batchTimer{{duration: _presetPrepare, label: "Get ready"}}
+	for i := 1; i <= p.rounds; i++ {
+		timers = append(timers, batchTimer{duration: p.work, label: fmt.Sprintf("%s %d/%d", p.label, i, p.rounds)})
+		if p.rest > 0 && i < p.rounds {
+			timers = append(timers, batchTimer{duration: p.rest, label: "Rest"})
+		}
+	}
+	if err := cmd.selectBatchSounds(timers, sound); err != nil {
+		return err
+	}
+	return cmd.batchSequential(timers)
+}
+
+// flagGiven returns whether the flag named name is given on the command line.
+func flagGiven(name string) bool {
+	given := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			given = true
+		}
+	})
+	return given
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+)
+
+var (
+	errUnknownProfile = withExitCode(_exitInvalidArgs, errors.New("Profile not found in the config file"))
+)
+
+// _profileFlags are the settings of a profile which are defaults of flags,
+// by the names of the flags they default. A flag given on the command line
+// under any of its names keeps its value.
+var _profileFlags = map[string][]string{
+	"notify":     {"notify", "n"},
+	"notify_via": {"notify-via"},
+	"volume":     {"volume"},
+}
+
+// applyProfile applies the settings of the [profile.NAME] section of the
+// config file over the other settings. Besides any other setting, a profile
+// sets the sound played by the timers and the defaults of -notify,
+// -notify-via and -volume. Settings of the environment take precedence over
+// those of the profile.
+func (cmd *Cmd) applyProfile(name string) error {
+	entries, ok := cmd.config.profiles[name]
+	if !ok {
+		return errUnknownProfile
+	}
+	for _, e := range entries {
+		if _, ok := os.LookupEnv(envName(e.key)); ok {
+			continue
+		}
+		if e.key == "sound" {
+			cmd.config.profileSound = e.value
+			continue
+		}
+		if names, ok := _profileFlags[e.key]; ok {
+			given := false
+			for _, n := range names {
+				given = given || flagGiven(n)
+			}
+			if given {
+				continue
+			}
+			if err := flag.Set(names[0], e.value); err != nil {
+				return fmt.Errorf("%s: %s: %v", e.where(), e.key, err)
+			}
+			continue
+		}
+		e.section = ""
+		if err := cmd.config.set(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkProfileEntry returns an error if the entry of a profile section is
+// not a setting of a profile or its value is invalid.
+func checkProfileEntry(e configEntry) error {
+	switch e.key {
+	case "sound", "notify_via":
+		return nil
+	case "notify":
+		if _, err := strconv.ParseBool(e.value); err != nil {
+			return fmt.Errorf("%s: notify must be true or false", e.where())
+		}
+		return nil
+	case "volume":
+		if v, err := strconv.Atoi(e.value); err != nil || v < 0 || v > 100 {
+			return fmt.Errorf("%s: volume must be a number from 0 to 100", e.where())
+		}
+		return nil
+	case "version":
+		return fmt.Errorf("%s: version cannot be set for a profile", e.where())
+	case "sounds_dir":
+		// The sound library is loaded before the profile is known
+		return fmt.Errorf("%s: sounds_dir cannot be set for a profile", e.where())
+	}
+	e.section = ""
+	cfg := &config{discord: make(map[string]discordConfig)}
+	return cfg.set(e)
+}
+
+// profiles processes the command (profiles).
+// List the names of the profiles of the config file sorted alphabetically.
+func (cmd *Cmd) profiles(args []string) error {
+	if len(args) != 0 {
+		fmt.Println("Unexpected arguments to profiles")
+		return errInvalidArgs
+	}
+	names := make([]string, 0, len(cmd.config.profiles))
+	for name := range cmd.config.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Println(name)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApplyProfile(t *testing.T) {
+	cmd := &Cmd{config: &config{
+		defaultUnit:    time.Minute,
+		pauseOnSuspend: true,
+		profiles: map[string][]configEntry{
+			"work": {
+				{section: "profile.work", key: "sound", value: "Chime", line: 2},
+				{section: "profile.work", key: "default_unit", value: "s", line: 3},
+				{section: "profile.work", key: "pause_on_suspend", value: "false", line: 4},
+			},
+		},
+	}}
+
+	if err := cmd.applyProfile("home"); err != errUnknownProfile {
+		t.Errorf("home: want %v got %v", errUnknownProfile, err)
+	}
+	if err := cmd.applyProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	if cmd.config.profileSound != "Chime" {
+		t.Errorf("sound: want Chime got %q", cmd.config.profileSound)
+	}
+	if cmd.config.defaultUnit != time.Second {
+		t.Errorf("default_unit: want %v got %v", time.Second, cmd.config.defaultUnit)
+	}
+	if cmd.config.pauseOnSuspend {
+		t.Error("pause_on_suspend: want false got true")
+	}
+}
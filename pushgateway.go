@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// pushgateway returns the URL of the Prometheus Pushgateway given with
+// -pushgateway or in the config file, if any.
+func (cmd *Cmd) pushgateway() string {
+	if cmd.args.pushgateway != "" {
+		return cmd.args.pushgateway
+	}
+	return cmd.config.pushgateway
+}
+
+// pushMetrics pushes the metrics of the end of the timer to the Pushgateway
+// at the URL, grouped by the job timer and the label of the timer. The
+// metrics of the group replace those of the last timer of the same label.
+func pushMetrics(url string, e timerEvent) error {
+	group := "/metrics/job/timer"
+	if e.Label != "" {
+		group += "/label@base64/" + base64.RawURLEncoding.EncodeToString([]byte(e.Label))
+	}
+	return postHook(strings.TrimSuffix(url, "/")+group,
+		http.Header{"Content-Type": {"text/plain; version=0.0.4"}}, metricsBody(e))
+}
+
+// metricsBody returns the metrics of the event in the Prometheus text format.
+func metricsBody(e timerEvent) []byte {
+	status := fmt.Sprintf("{status=%q}", e.Status)
+	var b strings.Builder
+	fmt.Fprintln(&b, "# HELP timer_duration_seconds Duration of the last timer.")
+	fmt.Fprintln(&b, "# TYPE timer_duration_seconds gauge")
+	fmt.Fprintf(&b, "timer_duration_seconds%s %g\n", status, e.Duration)
+	fmt.Fprintln(&b, "# HELP timer_elapsed_seconds Time the last timer ran until it ended.")
+	fmt.Fprintln(&b, "# TYPE timer_elapsed_seconds gauge")
+	fmt.Fprintf(&b, "timer_elapsed_seconds%s %g\n", status, e.FinishedAt.Sub(e.StartedAt).Seconds())
+	fmt.Fprintln(&b, "# HELP timer_last_end_timestamp_seconds Time the last timer ended.")
+	fmt.Fprintln(&b, "# TYPE timer_last_end_timestamp_seconds gauge")
+	fmt.Fprintf(&b, "timer_last_end_timestamp_seconds%s %g\n", status,
+		float64(e.FinishedAt.UnixNano())/float64(time.Second))
+	return []byte(b.String())
+}
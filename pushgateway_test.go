@@ -0,0 +1,44 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPushMetrics(t *testing.T) {
+	var path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		path, body = r.URL.Path, string(data)
+	}))
+	defer server.Close()
+
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	e := timerEvent{Label: "Deploy/freeze", Duration: 240, StartedAt: start, FinishedAt: start.Add(time.Minute), Status: _statusCancelled}
+	if err := pushMetrics(server.URL+"/", e); err != nil {
+		t.Fatal(err)
+	}
+	if want := "/metrics/job/timer/label@base64/RGVwbG95L2ZyZWV6ZQ"; path != want {
+		t.Errorf("want path %s got %s", want, path)
+	}
+	for _, want := range []string{
+		`timer_duration_seconds{status="cancelled"} 240` + "\n",
+		`timer_elapsed_seconds{status="cancelled"} 60` + "\n",
+		`timer_last_end_timestamp_seconds{status="cancelled"} 1.70928366e+09` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("no %q in\n%s", want, body)
+		}
+	}
+
+	if err := pushMetrics(server.URL, timerEvent{Status: _statusExpired}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "/metrics/job/timer"; path != want {
+		t.Errorf("without label: want path %s got %s", want, path)
+	}
+}
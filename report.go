@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	errUnknownPeriod = withExitCode(_exitInvalidArgs, errors.New("Unknown period, expected day or week"))
+)
+
+// width of the bar of the largest number of minutes of a report
+const _reportBarWidth = 40
+
+// report is the number of minutes timed in a period, per day and per label.
+type report struct {
+	Period string        `json:"period"`
+	From   time.Time     `json:"from"`
+	To     time.Time     `json:"to"`
+	Days   []reportTotal `json:"days"`
+	Labels []reportTotal `json:"labels"`
+}
+
+// reportTotal is the number of minutes timed on a day or with a label.
+type reportTotal struct {
+	Date    string  `json:"date,omitempty"`
+	Label   string  `json:"label,omitempty"`
+	Minutes float64 `json:"minutes"`
+}
+
+// reportCommand processes the command (report).
+// Show the minutes timed on each day and with each label of today, or of this
+// week with -period week, as a table with bars or with -format json.
+func (cmd *Cmd) reportCommand(args []string) error {
+	if len(args) != 0 {
+		fmt.Println("Unexpected arguments to report")
+		return errInvalidArgs
+	}
+	switch cmd.args.format {
+	case "text", "json":
+	default:
+		fmt.Println(errUnknownFormat)
+		return errUnknownFormat
+	}
+	from, to, err := reportPeriod(cmd.args.period, time.Now())
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+
+	entries, err := queryHistory(historyQuery{from: from, to: to})
+	if err != nil {
+		return fail("Error reading the history", err)
+	}
+	r := buildReport(cmd.args.period, from, to, entries)
+	if err := writeReport(os.Stdout, r, cmd.args.format); err != nil {
+		return fail("Error writing the report", err)
+	}
+	return nil
+}
+
+// reportPeriod returns the start of the day or the week, which starts on
+// Monday, of now and the start of the next one.
+func reportPeriod(period string, now time.Time) (time.Time, time.Time, error) {
+	y, m, d := now.Date()
+	switch period {
+	case "day":
+		return time.Date(y, m, d, 0, 0, 0, 0, now.Location()), time.Date(y, m, d+1, 0, 0, 0, 0, now.Location()), nil
+	case "week":
+		monday := d - (int(now.Weekday())+6)%7
+		return time.Date(y, m, monday, 0, 0, 0, 0, now.Location()), time.Date(y, m, monday+7, 0, 0, 0, 0, now.Location()), nil
+	}
+	return time.Time{}, time.Time{}, errUnknownPeriod
+}
+
+// buildReport returns the report of the entries, with every day of the
+// period and the labels by most minutes. Entries without label are counted
+// under an empty label, listed last.
+func buildReport(period string, from, to time.Time, entries []historyEntry) report {
+	r := report{Period: period, From: from, To: to, Days: []reportTotal{}, Labels: []reportTotal{}}
+	days, labels := make(map[string]float64), make(map[string]float64)
+	for _, e := range entries {
+		minutes := e.timed() / 60
+		days[e.Start.In(from.Location()).Format(_dayLayout)] += minutes
+		labels[e.Label] += minutes
+	}
+
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(_dayLayout)
+		r.Days = append(r.Days, reportTotal{Date: date, Minutes: days[date]})
+	}
+	for label, minutes := range labels {
+		r.Labels = append(r.Labels, reportTotal{Label: label, Minutes: minutes})
+	}
+	sort.Slice(r.Labels, func(i, j int) bool {
+		a, b := r.Labels[i], r.Labels[j]
+		if (a.Label == "") != (b.Label == "") {
+			return b.Label == ""
+		}
+		if a.Minutes != b.Minutes {
+			return a.Minutes > b.Minutes
+		}
+		return a.Label < b.Label
+	})
+	return r
+}
+
+// timed returns the seconds the entry was timed: the duration and the
+// overtime of an expired timer, and the time a cancelled one ran.
+func (e historyEntry) timed() float64 {
+	if e.status() == _statusCancelled {
+		return e.Elapsed
+	}
+	return e.Duration + e.Overtime
+}
+
+// writeReport writes the report as a table with bars, or in the format json.
+func writeReport(w io.Writer, r report, format string) error {
+	if format == "json" {
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+
+	most := 0.0
+	for _, t := range append(append([]reportTotal(nil), r.Days...), r.Labels...) {
+		if t.Minutes > most {
+			most = t.Minutes
+		}
+	}
+	bar := func(minutes float64) string {
+		if minutes == 0 {
+			return ""
+		}
+		return " " + strings.Repeat("█", int(minutes/most*_reportBarWidth+0.5))
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DAY\tMINUTES")
+	for _, t := range r.Days {
+		day, _ := time.ParseInLocation(_dayLayout, t.Date, r.From.Location())
+		fmt.Fprintf(tw, "%s\t%7.0f%s\n", day.Format("Mon 2006-01-02"), t.Minutes, bar(t.Minutes))
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "LABEL\tMINUTES")
+	for _, t := range r.Labels {
+		label := t.Label
+		if label == "" {
+			label = "(no label)"
+		}
+		fmt.Fprintf(tw, "%s\t%7.0f%s\n", label, t.Minutes, bar(t.Minutes))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportPeriod(t *testing.T) {
+	// A Wednesday
+	now := time.Date(2024, 3, 6, 15, 4, 5, 0, time.UTC)
+	from, to, err := reportPeriod("week", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC); !from.Equal(want) || !to.Equal(want.AddDate(0, 0, 7)) {
+		t.Errorf("week: want from %v got %v to %v", want, from, to)
+	}
+	from, to, _ = reportPeriod("day", now)
+	if want := time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC); !from.Equal(want) || !to.Equal(want.AddDate(0, 0, 1)) {
+		t.Errorf("day: want from %v got %v to %v", want, from, to)
+	}
+	if _, _, err := reportPeriod("month", now); err != errUnknownPeriod {
+		t.Errorf("month: want %v got %v", errUnknownPeriod, err)
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	from := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	entries := []historyEntry{
+		{Kind: _historyPomodoro, Start: from.Add(9 * time.Hour), Duration: 1500},
+		{Kind: _historyTimer, Label: "tea", Start: from.Add(10 * time.Hour), Duration: 240, Overtime: 60},
+		{Kind: _historyTimer, Label: "tea", Start: from.AddDate(0, 0, 2), Duration: 600, Status: _statusCancelled, Elapsed: 120},
+		{Kind: _historyTrack, Label: "writing", Start: from.AddDate(0, 0, 2), Duration: 3600, Status: _statusStopped},
+	}
+	r := buildReport("week", from, from.AddDate(0, 0, 7), entries)
+
+	if len(r.Days) != 7 {
+		t.Fatalf("want 7 days got %+v", r.Days)
+	}
+	for i, want := range []float64{30, 0, 62} {
+		if r.Days[i].Minutes != want {
+			t.Errorf("day %s: want %v minutes got %v", r.Days[i].Date, want, r.Days[i].Minutes)
+		}
+	}
+	want := []reportTotal{{Label: "writing", Minutes: 60}, {Label: "tea", Minutes: 7}, {Minutes: 25}}
+	if len(r.Labels) != len(want) {
+		t.Fatalf("want labels %+v got %+v", want, r.Labels)
+	}
+	for i := range want {
+		if r.Labels[i] != want[i] {
+			t.Errorf("want label %+v got %+v", want[i], r.Labels[i])
+		}
+	}
+
+	var b strings.Builder
+	if err := writeReport(&b, r, "text"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "Wed 2024-03-06       62 "+strings.Repeat("█", _reportBarWidth)+"\n") {
+		t.Errorf("no full bar for the longest day in\n%s", b.String())
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	// radius of the ring of -ring in braille dots, a dot being as wide as it
+	// is high, and the thickness of the ring
+	_ringRadius    = 9
+	_ringThickness = 2.5
+)
+
+// _brailleDots are the bits of the dots of a braille character, by row and
+// column of the 4 rows of 2 dots of the character.
+var _brailleDots = [4][2]rune{{0x01, 0x08}, {0x02, 0x10}, {0x04, 0x20}, {0x40, 0x80}}
+
+// ringLines returns the lines of braille characters drawing a ring of the
+// radius in dots, of which the part remaining of the timer is lit. The part
+// passed, the fraction from 0 to 1, is taken away clockwise from the top.
+func ringLines(fraction float64, radius int) []string {
+	size := 2*radius + 1
+	cols, rows := (size+1)/2, (size+3)/4
+	cells := make([][]rune, rows)
+	for i := range cells {
+		cells[i] = make([]rune, cols)
+	}
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := float64(x-radius), float64(y-radius)
+			if d := math.Hypot(dx, dy); d > float64(radius)+0.5 || d < float64(radius)-_ringThickness {
+				continue
+			}
+			// Clockwise angle from the top, from 0 to 1
+			angle := math.Atan2(dx, -dy) / (2 * math.Pi)
+			if angle < 0 {
+				angle++
+			}
+			if angle >= fraction {
+				cells[y/4][x/2] |= _brailleDots[y%4][x%2]
+			}
+		}
+	}
+
+	lines := make([]string, rows)
+	for i, row := range cells {
+		var b strings.Builder
+		for _, dots := range row {
+			b.WriteRune(0x2800 + dots)
+		}
+		lines[i] = strings.TrimRight(b.String(), "⠀")
+	}
+	return lines
+}
+
+// drawRing processes -ring.
+// Draw the ring of the part remaining of the timer above the progress line,
+// over the ring drawn last if any. The cursor is left on the progress line.
+func (cmd *Cmd) drawRing(fraction float64) {
+	if cmd.ringLines > 0 {
+		fmt.Printf("\r\x1b[%dA", cmd.ringLines)
+	} else {
+		fmt.Print("\r\x1b[K")
+	}
+	lines := ringLines(fraction, _ringRadius)
+	for _, line := range lines {
+		fmt.Printf("\x1b[K%s\n", line)
+	}
+	cmd.ringLines = len(lines)
+}
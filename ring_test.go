@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestRingLines(t *testing.T) {
+	full := ringLines(0, _ringRadius)
+	if len(full) != 5 {
+		t.Fatalf("want 5 lines got %d:\n%s", len(full), strings.Join(full, "\n"))
+	}
+	for i, line := range full {
+		if line == "" {
+			t.Errorf("line %d of the full ring is empty", i)
+		}
+		if n := utf8.RuneCountInString(line); n > 10 {
+			t.Errorf("line %d is %d characters wide", i, n)
+		}
+	}
+
+	for _, line := range ringLines(1, _ringRadius) {
+		if line != "" {
+			t.Errorf("want an empty ring once expired got %q", line)
+		}
+	}
+
+	// Half way through only the left half remains lit
+	for i, line := range ringLines(0.5, _ringRadius) {
+		runes := []rune(line)
+		if len(runes) > 5 {
+			t.Errorf("line %d of the half ring reaches the right half: %q", i, line)
+		}
+		if len(runes) == 0 {
+			t.Errorf("line %d of the half ring is empty", i)
+		}
+	}
+}
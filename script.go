@@ -0,0 +1,64 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// script is a Lua script given with -script whose functions on_start,
+// on_tick, on_expire and on_cancel are called on the events of the timers.
+type script struct {
+	state *lua.LState
+}
+
+// loadScript runs the Lua script in the file, defining its functions.
+func loadScript(file string) (*script, error) {
+	state := lua.NewState()
+	if err := state.DoFile(file); err != nil {
+		state.Close()
+		return nil, err
+	}
+	return &script{state: state}, nil
+}
+
+// call calls the function of the script named name, if it defines one, with
+// the state of the timer started at start and expiring at end. It returns
+// false if the function returned false, true otherwise, so that a script
+// only changes what happens by returning false.
+func (s *script) call(name string, label string, start, end time.Time) bool {
+	fn := s.state.GetGlobal(name)
+	if fn.Type() != lua.LTFunction {
+		return true
+	}
+
+	passed, remaining := time.Since(start), time.Until(end)
+	if remaining < 0 {
+		remaining = 0
+	}
+	timer := s.state.NewTable()
+	s.state.SetField(timer, "label", lua.LString(label))
+	s.state.SetField(timer, "duration", lua.LNumber(end.Sub(start).Seconds()))
+	s.state.SetField(timer, "passed", lua.LNumber(passed.Round(time.Second).Seconds()))
+	s.state.SetField(timer, "remaining", lua.LNumber(remaining.Round(time.Second).Seconds()))
+	s.state.SetField(timer, "started_at", lua.LNumber(start.Unix()))
+	s.state.SetField(timer, "ends_at", lua.LNumber(end.Unix()))
+
+	if err := s.state.CallByParam(lua.P{Fn: fn, NRet: 1, Protect: true}, timer); err != nil {
+		slog.Warn("the script failed", "function", name, "err", err)
+		return true
+	}
+	ret := s.state.Get(-1)
+	s.state.Pop(1)
+	return ret != lua.LFalse
+}
+
+// runScript calls the function of the -script named name for the running
+// timer, and returns whether the script lets the timer go on as usual.
+func (cmd *Cmd) runScript(name string, start, end time.Time) bool {
+	if cmd.script == nil {
+		return true
+	}
+	return cmd.script.call(name, cmd.label, start, end)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScript(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "hooks.lua")
+	os.WriteFile(file, []byte(`
+seen = ""
+function on_start(timer)
+  seen = timer.label .. " " .. timer.duration
+end
+function on_expire(timer)
+  return timer.label ~= "Quiet"
+end
+function on_cancel(timer)
+  error("broken")
+end
+`), 0644)
+
+	s, err := loadScript(file)
+	if err != nil {
+		t.Fatalf("loadScript failed: %v", err)
+	}
+	start := time.Now()
+	end := start.Add(4 * time.Minute)
+	if !s.call("on_start", "Tea", start, end) {
+		t.Error("on_start without a result returned false")
+	}
+	if seen := s.state.GetGlobal("seen").String(); seen != "Tea 240" {
+		t.Errorf("on_start saw %q, want Tea 240", seen)
+	}
+	if !s.call("on_expire", "Tea", start, end) || s.call("on_expire", "Quiet", start, end) {
+		t.Error("on_expire did not return false for Quiet only")
+	}
+	if !s.call("on_cancel", "Tea", start, end) {
+		t.Error("a failed function returned false")
+	}
+	if !s.call("on_tick", "Tea", start, end) {
+		t.Error("a missing function returned false")
+	}
+
+	os.WriteFile(file, []byte("function ("), 0644)
+	if _, err := loadScript(file); err == nil {
+		t.Error("loadScript of an invalid script did not fail")
+	}
+}
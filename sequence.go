@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	errNoSteps = errors.New("No steps in sequence")
+)
+
+// sequence is an ordered list of timers run back to back, read from a
+// sequence file.
+type sequence struct {
+	name string
+	// sound of the steps without a sound of their own
+	sound string
+	// number of times the steps are run
+	repeat int
+	steps  []batchTimer
+}
+
+// sequenceFile is the YAML document of a sequence file:
+//
+//	name: Workout
+//	sound: Bell
+//	repeat: 3
+//	steps:
+//	  - duration: 30s
+//	    label: Jumping jacks
+//	    sound: Gong
+//	    notify: true
+type sequenceFile struct {
+	Name   string `yaml:"name"`
+	Sound  string `yaml:"sound"`
+	Repeat *int   `yaml:"repeat"`
+	Steps  []struct {
+		Duration string `yaml:"duration"`
+		Label    string `yaml:"label"`
+		Sound    string `yaml:"sound"`
+		Notify   bool   `yaml:"notify"`
+	} `yaml:"steps"`
+}
+
+// parseSequence reads a sequence file, a YAML document whose unknown keys
+// are rejected. A duration without a unit is in the unit.
+func parseSequence(r io.Reader, unit time.Duration) (*sequence, error) {
+	var f sequenceFile
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err == io.EOF {
+		return nil, errNoSteps
+	} else if err != nil {
+		return nil, fmt.Errorf("sequence: %v", strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+
+	seq := &sequence{name: f.Name, sound: f.Sound, repeat: 1}
+	if f.Repeat != nil {
+		if *f.Repeat < 1 {
+			return nil, fmt.Errorf("sequence: repeat must be a positive number")
+		}
+		seq.repeat = *f.Repeat
+	}
+	if len(f.Steps) == 0 {
+		return nil, errNoSteps
+	}
+	for i, s := range f.Steps {
+		if s.Duration == "" {
+			return nil, fmt.Errorf("step %d: missing duration", i+1)
+		}
+		d, err := parseTime(s.Duration, unit)
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %v", i+1, err)
+		}
+		step := batchTimer{duration: d, label: s.Label, sound: s.Sound, notify: s.Notify}
+		if step.label == "" {
+			step.label = fmt.Sprintf("Step %d", i+1)
+		}
+		seq.steps = append(seq.steps, step)
+	}
+	return seq, nil
+}
+
+// runSequence processes the command (run FILE).
+// Run the steps of the sequence file one after the other, playing the sound
+// and showing the notification of each step when it ends.
+func (cmd *Cmd) runSequence(args []string) error {
+	if len(args) != 1 {
+		fmt.Println("Expected the sequence file")
+		return errInvalidArgs
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fail("Error opening the sequence file", err)
+	}
+	defer f.Close()
+
+	seq, err := parseSequence(f, cmd.config.defaultUnit)
+	if err != nil {
+		fmt.Println("Error reading the sequence:", err)
+		return err
+	}
+
+	sound := seq.sound
+	if sound == "" {
+		sound = cmd.args.sound
+	}
+	if err := cmd.selectBatchSounds(seq.steps, sound); err != nil {
+		return err
+	}
+
+	var timers []batchTimer
+	for i := 0; i < seq.repeat; i++ {
+		timers = append(timers, seq.steps...)
+	}
+	if seq.name != "" {
+		fmt.Println(seq.name)
+	}
+	return cmd.batchSequential(timers)
+}
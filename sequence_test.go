@@ -0,0 +1,74 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSequence(t *testing.T) {
+	in := `# warm up first
+name: "Morning workout"
+repeat: 2
+steps:
+  - duration: 30s
+    label: Jumping jacks   # the hard part
+    sound: Gong
+    notify: true
+
+  -
+    duration: 10
+`
+	want := &sequence{
+		name:   "Morning workout",
+		repeat: 2,
+		steps: []batchTimer{
+			{30 * time.Second, "Jumping jacks", "Gong", true},
+			{10 * time.Minute, "Step 2", "", false},
+		},
+	}
+	got, err := parseSequence(strings.NewReader(in), time.Minute)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("want %+v got %+v, %v", want, got, err)
+	}
+
+	// Single quotes, flow mappings and anchors are YAML as well
+	in = `name: 'Tabata'
+steps:
+  - &work {duration: 20s, label: 'Work', sound: Gong}
+  - {duration: 10s, label: Rest}
+  - *work
+`
+	want = &sequence{
+		name:   "Tabata",
+		repeat: 1,
+		steps: []batchTimer{
+			{20 * time.Second, "Work", "Gong", false},
+			{10 * time.Second, "Rest", "", false},
+			{20 * time.Second, "Work", "Gong", false},
+		},
+	}
+	got, err = parseSequence(strings.NewReader(in), time.Minute)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("want %+v got %+v, %v", want, got, err)
+	}
+
+	for _, in := range []string{
+		"",
+		"steps:\n",
+		"steps:\n  - label: No duration\n",
+		"steps:\n  - duration: ten\n",
+		"steps:\n  - duration: 1m\n    color: red\n",
+		"steps:\n  - duration: 1m\n    notify: maybe\n",
+		"steps:\n  duration: 1m\n",
+		"steps:\n\t- duration: 1m\n",
+		"  name: Indented\n",
+		"repeat: 0\nsteps:\n  - duration: 1m\n",
+		"title: Workout\n",
+	} {
+		if _, err := parseSequence(strings.NewReader(in), time.Minute); err == nil {
+			t.Errorf("%q: want an error", in)
+		}
+	}
+}
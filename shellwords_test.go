@@ -0,0 +1,42 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"ffplay -nodisp FILE", []string{"ffplay", "-nodisp", "FILE"}},
+		{"  mpv   FILE  ", []string{"mpv", "FILE"}},
+		{`player "my file.mp3"`, []string{"player", "my file.mp3"}},
+		{`player 'it''s'`, []string{"player", "its"}},
+		{`player my\ file.mp3`, []string{"player", "my file.mp3"}},
+		{`player "say \"hi\""`, []string{"player", `say "hi"`}},
+		{`C:\tools\ffplay.exe FILE`, []string{`C:\tools\ffplay.exe`, "FILE"}},
+		{`sh -c "echo 'FILE'"`, []string{"sh", "-c", "echo 'FILE'"}},
+		{`player ""`, []string{"player", ""}},
+	}
+
+	for _, tt := range tests {
+		got, err := splitWords(tt.in)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("%s: want %q got %q", tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestSplitWordsUnterminated(t *testing.T) {
+	for _, in := range []string{`player "FILE`, `player 'FILE`} {
+		if _, err := splitWords(in); err != errUnterminatedQuote {
+			t.Errorf("%s: want %v got %v", in, errUnterminatedQuote, err)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// slackWebhook returns the URL of the Slack incoming webhook given with
+// -slack-webhook or in the config file, if any.
+func (cmd *Cmd) slackWebhook() string {
+	if cmd.args.slackWebhook != "" {
+		return cmd.args.slackWebhook
+	}
+	return cmd.config.slackWebhook
+}
+
+// sendSlack posts the message telling how the timer ended to the channel of
+// the Slack incoming webhook at the URL.
+func sendSlack(url string, e timerEvent) error {
+	body, err := json.Marshal(struct {
+		Text string `json:"text"`
+	}{":alarm_clock: " + e.text()})
+	if err != nil {
+		return err
+	}
+	return postHook(url, http.Header{"Content-Type": {"application/json"}}, body)
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSendSlack(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	e := timerEvent{Label: "Deploy freeze", Status: _statusExpired}
+	if err := sendSlack(server.URL, e); err != nil {
+		t.Fatalf("sendSlack failed: %v", err)
+	}
+	if want := `{"text":":alarm_clock: Deploy freeze: ` + tr(_msgNotifyText) + `"}`; body != want {
+		t.Errorf("slack message = %s, want %s", body, want)
+	}
+}
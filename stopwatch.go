@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	errUnknownFormat = withExitCode(_exitInvalidArgs, errors.New("Format must be text, json or csv"))
+)
+
+// how often the stopwatch is updated, it shows tenths of a second
+const _stopwatchResolution = 100 * time.Millisecond
+
+// lap is a lap recorded by the stopwatch, its split time and the time since
+// the stopwatch was started in seconds.
+type lap struct {
+	Lap   int     `json:"lap"`
+	Split float64 `json:"split"`
+	Total float64 `json:"total"`
+}
+
+// stopwatch processes the command (stopwatch).
+// Count up from zero until q or Enter is pressed, l or space records a lap.
+// The laps are printed when the stopwatch stops, with -format json or csv in
+// that format, and with -file written to the file instead.
+func (cmd *Cmd) stopwatch(args []string) error {
+	if len(args) != 0 {
+		fmt.Println("Unexpected arguments to stopwatch")
+		return errInvalidArgs
+	}
+	switch cmd.args.format {
+	case "text", "json", "csv":
+	default:
+		fmt.Println(errUnknownFormat)
+		return errUnknownFormat
+	}
+
+	keys, restore := terminalKeys()
+	defer restore()
+
+	fmt.Println("Press l or space to record a lap, q or enter to stop")
+	start := time.Now()
+	ticker := time.NewTicker(_stopwatchResolution)
+	defer ticker.Stop()
+
+	var laps []lap
+	last := time.Duration(0)
+	cmd.showStopwatch(0)
+	for done := false; !done; {
+		select {
+		case <-ticker.C:
+			cmd.showStopwatch(time.Since(start))
+		case key, ok := <-keys:
+			if !ok {
+				// Without standard input only an interrupt stops the stopwatch
+				keys = nil
+				continue
+			}
+			switch key {
+			case "l", " ":
+				total := time.Since(start)
+				l := lap{Lap: len(laps) + 1, Split: (total - last).Seconds(), Total: total.Seconds()}
+				laps, last = append(laps, l), total
+				cmd.clearLine()
+				fmt.Printf("Lap %-3d %s  %s\n", l.Lap, formatSplit(l.duration()), formatSplit(total))
+				cmd.showStopwatch(total)
+			case "q", "\r", "\n":
+				done = true
+			}
+		case <-cmd.ctx.Done():
+			done = true
+		}
+	}
+
+	total := time.Since(start)
+	cmd.clearLine()
+	fmt.Println("Stopped at", formatSplit(total))
+	// The time after the last lap is a lap of its own
+	if len(laps) > 0 && total > last {
+		laps = append(laps, lap{Lap: len(laps) + 1, Split: (total - last).Seconds(), Total: total.Seconds()})
+	}
+
+	var w io.Writer = os.Stdout
+	if cmd.args.file != "" {
+		f, err := os.Create(cmd.args.file)
+		if err != nil {
+			return fail("Error writing the laps", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := writeLaps(w, laps, cmd.args.format); err != nil {
+		return fail("Error writing the laps", err)
+	}
+	return nil
+}
+
+// duration returns the split time of the lap.
+func (l lap) duration() time.Duration {
+	return time.Duration(l.Split * float64(time.Second))
+}
+
+// writeLaps writes the laps in the format, text, json or csv.
+func writeLaps(w io.Writer, laps []lap, format string) error {
+	switch format {
+	case "json":
+		if laps == nil {
+			laps = []lap{}
+		}
+		data, err := json.MarshalIndent(laps, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"lap", "split", "total"})
+		for _, l := range laps {
+			cw.Write([]string{
+				strconv.Itoa(l.Lap), strconv.FormatFloat(l.Split, 'f', 3, 64), strconv.FormatFloat(l.Total, 'f', 3, 64),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	if len(laps) == 0 {
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LAP\tSPLIT\tTOTAL")
+	for _, l := range laps {
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", l.Lap, formatSplit(l.duration()),
+			formatSplit(time.Duration(l.Total*float64(time.Second))))
+	}
+	return tw.Flush()
+}
+
+// formatSplit returns d like a clock with tenths of a second, like 02:13.4.
+func formatSplit(d time.Duration) string {
+	return fmt.Sprintf("%s.%d", formatClock(d), d%time.Second/(100*time.Millisecond))
+}
+
+// showStopwatch shows the time passed on the current line.
+func (cmd *Cmd) showStopwatch(passed time.Duration) {
+	if cmd.ansi {
+		fmt.Printf("\r\x1b[K⏱  %s", formatSplit(passed))
+		return
+	}
+	fmt.Printf("\r%-79s", formatSplit(passed))
+}
+
+// clearLine clears the current line, so that a line can be printed in place
+// of the progress shown on it.
+func (cmd *Cmd) clearLine() {
+	// A ring above the progress stays, the next one is drawn below it
+	cmd.ringLines = 0
+	if cmd.ansi {
+		fmt.Print("\r\x1b[K")
+		return
+	}
+	fmt.Printf("\r%79s\r", "")
+}
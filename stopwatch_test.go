@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteLaps(t *testing.T) {
+	laps := []lap{{1, 5.2, 5.2}, {2, 62.35, 67.55}}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"text", "LAP  SPLIT    TOTAL\n1    00:05.2  00:05.2\n2    01:02.3  01:07.5\n"},
+		{"csv", "lap,split,total\n1,5.200,5.200\n2,62.350,67.550\n"},
+		{"json", `[
+  {
+    "lap": 1,
+    "split": 5.2,
+    "total": 5.2
+  },
+  {
+    "lap": 2,
+    "split": 62.35,
+    "total": 67.55
+  }
+]
+`},
+	}
+
+	for _, tt := range tests {
+		var b bytes.Buffer
+		if err := writeLaps(&b, laps, tt.format); err != nil || b.String() != tt.want {
+			t.Errorf("%s: want %q got %q, %v", tt.format, tt.want, b.String(), err)
+		}
+	}
+
+	var b bytes.Buffer
+	if writeLaps(&b, nil, "json"); b.String() != "[]\n" {
+		t.Errorf("want an empty list got %q", b.String())
+	}
+}
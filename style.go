@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
+var (
+	errUnknownStyle = withExitCode(_exitInvalidArgs, errors.New("Unknown progress style, expected text, bar, blocks, dots, spinner or none"))
+)
+
+const (
+	// width of the bars of the progress styles, in characters
+	_styleWidth = 20
+	// time each frame of the spinner is shown
+	_spinnerFrame = 100 * time.Millisecond
+)
+
+var (
+	// _spinner are the frames of the spinner style, and _asciiSpinner those
+	// shown on terminals without Unicode
+	_spinner      = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	_asciiSpinner = []string{"|", "/", "-", "\\"}
+	// _eighths are the blocks filling the last cell of a bar by eighths
+	_eighths = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+)
+
+// progressStyle returns the style of the progress given with -style or in
+// the config file, text unless given.
+func (cmd *Cmd) progressStyle() string {
+	if cmd.args.style != "" {
+		return cmd.args.style
+	}
+	if cmd.config.progressStyle != "" {
+		return cmd.config.progressStyle
+	}
+	return "text"
+}
+
+// checkStyle returns an error if the style of the progress is not known.
+func checkStyle(style string) error {
+	switch style {
+	case "text", "bar", "blocks", "dots", "spinner", "none":
+		return nil
+	}
+	return errUnknownStyle
+}
+
+// unicodeTerminal reports whether the locale of the terminal is UTF-8, which
+// shows the blocks, dots and spinner of the styles. The locale is read from
+// LC_ALL, then LC_CTYPE and LANG. The console of Windows shows them whatever
+// its code page.
+func unicodeTerminal() bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := strings.ToLower(os.Getenv(name)); locale != "" {
+			return strings.Contains(locale, "utf-8") || strings.Contains(locale, "utf8")
+		}
+	}
+	return runtime.GOOS == "windows"
+}
+
+// styledProgress returns the progress of pc percent with the remaining time
+// in the style, other than text: a bar of # or of blocks, dots, a spinner
+// turning while the timer runs, or none for the percentage alone. The bar of
+// blocks advances by eighths of a character. Without unicode the blocks are
+// drawn like the bar of #, and the dots and the spinner with ASCII. The
+// remaining time, or the percentage alone, is colored by paint.
+func styledProgress(style string, unicode bool, pc int, passed, remaining, total time.Duration, paint func(string) string) string {
+	// The remaining seconds are rounded up, so that 00:00 shows on expiry
+	remaining = (remaining + time.Second - 1).Truncate(time.Second)
+	left := formatClock(remaining)
+	if total >= 24*time.Hour {
+		left = formatDays(remaining)
+	}
+	left = paint(left)
+	filled := pc * _styleWidth / 100
+	percent := fmt.Sprintf("%3d%%", pc)
+
+	if !unicode && style == "blocks" {
+		style = "bar"
+	}
+	switch style {
+	case "bar":
+		return "[" + strings.Repeat("#", filled) + strings.Repeat("-", _styleWidth-filled) + "] " + percent + " " + left
+	case "blocks":
+		return "▕" + smoothBar(passed, total, _styleWidth) + "▏ " + percent + " " + left
+	case "dots":
+		full, empty := "●", "○"
+		if !unicode {
+			full, empty = "o", "."
+		}
+		dots := pc * 10 / 100
+		return strings.Repeat(full, dots) + strings.Repeat(empty, 10-dots) + " " + left
+	case "spinner":
+		frames, done := _spinner, "✔"
+		if !unicode {
+			frames, done = _asciiSpinner, "*"
+		}
+		frame := frames[int(passed/_spinnerFrame)%len(frames)]
+		if pc == 100 {
+			frame = done
+		}
+		return frame + " " + left
+	}
+	return paint(percent)
+}
+
+// smoothBar returns the bar of width characters filled by the part of total
+// passed, to an eighth of a character, padded with spaces.
+func smoothBar(passed, total time.Duration, width int) string {
+	eighths := width * 8
+	if total > 0 && passed < total {
+		eighths = int(int64(passed) * int64(width*8) / int64(total))
+	}
+	full, part := eighths/8, eighths%8
+	bar := strings.Repeat("█", full) + _eighths[part]
+	if part > 0 {
+		full++
+	}
+	return bar + strings.Repeat(" ", width-full)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStyledProgress(t *testing.T) {
+	passed, remaining, total := 150*time.Second, 350*time.Second, 500*time.Second
+	plain := func(s string) string { return s }
+	tests := []struct {
+		style string
+		want  string
+	}{
+		{"bar", "[######--------------]  30% 05:50"},
+		{"blocks", "▕██████              ▏  30% 05:50"},
+		{"dots", "●●●○○○○○○○ 05:50"},
+		{"spinner", "⠋ 05:50"},
+		{"none", " 30%"},
+	}
+	for _, test := range tests {
+		if got := styledProgress(test.style, true, 30, passed, remaining, total, plain); got != test.want {
+			t.Errorf("%s: want %q got %q", test.style, test.want, got)
+		}
+	}
+	if got := styledProgress("spinner", true, 30, passed+_spinnerFrame, remaining, total, plain); got != "⠙ 05:50" {
+		t.Errorf("spinner: want the next frame got %q", got)
+	}
+	if got := styledProgress("spinner", true, 100, total, 0, total, plain); got != "✔ 00:00" {
+		t.Errorf("spinner: want a check mark once expired got %q", got)
+	}
+	if got := styledProgress("blocks", false, 30, passed, remaining, total, plain); got != "[######--------------]  30% 05:50" {
+		t.Errorf("blocks without unicode: want the bar of # got %q", got)
+	}
+	if got := styledProgress("spinner", false, 30, passed, remaining, total, plain); got != "| 05:50" {
+		t.Errorf("spinner without unicode: want an ASCII frame got %q", got)
+	}
+	if err := checkStyle("rainbow"); err != errUnknownStyle {
+		t.Errorf("want %v got %v", errUnknownStyle, err)
+	}
+}
+
+func TestSmoothBar(t *testing.T) {
+	tests := []struct {
+		passed time.Duration
+		want   string
+	}{
+		{0, "     "},
+		{time.Second, "▏    "},
+		{5 * time.Second, "▋    "},
+		{22 * time.Second, "██▊  "},
+		{40 * time.Second, "█████"},
+		{time.Minute, "█████"},
+	}
+	for _, test := range tests {
+		if got := smoothBar(test.passed, 40*time.Second, 5); got != test.want {
+			t.Errorf("%v: want %q got %q", test.passed, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	errNoSyncURL = withExitCode(_exitInvalidArgs, errors.New("No sync_url in the config file to sync with"))
+)
+
+// Remote files of the synced state, next to sync_url
+const (
+	_syncConfig  = "config.toml"
+	_syncHistory = "history.jsonl"
+)
+
+// syncConfig is the WebDAV server, or any HTTP endpoint taking GET and PUT
+// requests, the config file and the history are synced with.
+type syncConfig struct {
+	url      string
+	user     string
+	password string
+}
+
+// syncState is the state of the last sync, kept next to the config file.
+type syncState struct {
+	// time of the last sync of the config file
+	Config time.Time `json:"config"`
+	// hash of the config file synced last, on both sides after the sync
+	ConfigHash string `json:"config_hash,omitempty"`
+}
+
+// getSyncStateFile returns the location of the state of the last sync.
+func getSyncStateFile() string {
+	return filepath.Join(getConfigDir(), "sync.json")
+}
+
+// syncCommand processes the command (sync).
+// Merge the history with the one of the sync_url of the config file, and
+// keep the newest of the local and the remote config file on both sides. A
+// config file changed on both sides since the last sync is replaced by the
+// newest one, the other one is kept as config.toml.conflict.
+func (cmd *Cmd) syncCommand(args []string) error {
+	if len(args) != 0 {
+		fmt.Println("Unexpected arguments to sync")
+		return errInvalidArgs
+	}
+	s := cmd.config.sync
+	if s.url == "" {
+		fmt.Println(errNoSyncURL)
+		return errNoSyncURL
+	}
+
+	added, sent, err := s.syncHistory(cmd.ctx)
+	if err != nil {
+		return fail("Error syncing the history", err)
+	}
+	fmt.Printf("History: %d timers received, %d sent\n", added, sent)
+
+	result, err := s.syncConfigFile(cmd.ctx)
+	if err != nil {
+		return fail("Error syncing the config file", err)
+	}
+	fmt.Println("Config file:", result)
+	return nil
+}
+
+// syncHistory adds the remote entries missing from the local history to it,
+// and sends the merged history if the remote one misses any local entry. It
+// returns the number of entries received and sent.
+func (s syncConfig) syncHistory(ctx context.Context) (int, int, error) {
+	data, _, err := s.get(ctx, _syncHistory)
+	if err != nil {
+		return 0, 0, err
+	}
+	remote, err := parseHistoryLines(data)
+	if err != nil {
+		return 0, 0, fmt.Errorf("remote %s: %v", _syncHistory, err)
+	}
+	local, err := queryHistory(historyQuery{})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	added, sent, merged := mergeHistory(local, remote)
+	if len(added) > 0 {
+		if err := addHistory(added); err != nil {
+			return 0, 0, err
+		}
+	}
+	if sent > 0 {
+		var b bytes.Buffer
+		for _, e := range merged {
+			line, err := json.Marshal(e)
+			if err != nil {
+				return 0, 0, err
+			}
+			b.Write(append(line, '\n'))
+		}
+		if err := s.put(ctx, _syncHistory, b.Bytes()); err != nil {
+			return 0, 0, err
+		}
+	}
+	return len(added), sent, nil
+}
+
+// mergeHistory returns the remote entries missing from the local ones, the
+// number of local entries missing from the remote ones, and all the entries
+// sorted by start. Entries are the same if all their fields are.
+func mergeHistory(local, remote []historyEntry) ([]historyEntry, int, []historyEntry) {
+	key := func(e historyEntry) string {
+		data, _ := json.Marshal(e)
+		return string(data)
+	}
+	localKeys, remoteKeys := make(map[string]bool), make(map[string]bool)
+	for _, e := range local {
+		localKeys[key(e)] = true
+	}
+	for _, e := range remote {
+		remoteKeys[key(e)] = true
+	}
+
+	merged := append([]historyEntry(nil), remote...)
+	sent := 0
+	for _, e := range local {
+		if !remoteKeys[key(e)] {
+			merged = append(merged, e)
+			sent++
+		}
+	}
+	var added []historyEntry
+	for _, e := range remote {
+		if !localKeys[key(e)] {
+			added = append(added, e)
+			// A duplicate remote entry is added once
+			localKeys[key(e)] = true
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].Start.Before(merged[j].Start) })
+	return added, sent, merged
+}
+
+// addHistory records the entries in the history.
+func addHistory(entries []historyEntry) error {
+	db, err := openHistory()
+	if err != nil {
+		return err
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, e := range entries {
+			if err := putHistory(tx, e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		db.Close()
+		return err
+	}
+	return db.Close()
+}
+
+// parseHistoryLines parses history entries of one JSON object per line.
+func parseHistoryLines(data []byte) ([]historyEntry, error) {
+	var entries []historyEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e historyEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// Ways the config files are synced
+const (
+	_syncSame     = "unchanged"
+	_syncSent     = "sent"
+	_syncReceived = "received"
+	// changed on both sides since the last sync
+	_syncSentConflict     = "sent, the remote one changed as well and is kept as config.toml.conflict"
+	_syncReceivedConflict = "received, the local one changed as well and is kept as config.toml.conflict"
+)
+
+// syncConfigFile keeps the newest of the local and the remote config file on
+// both sides, and returns how.
+func (s syncConfig) syncConfigFile(ctx context.Context) (string, error) {
+	file := getConfigFile()
+	local, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return "", err
+	}
+	remote, remoteMod, err := s.get(ctx, _syncConfig)
+	if err != nil {
+		return "", err
+	}
+
+	var state syncState
+	if data, err := ioutil.ReadFile(getSyncStateFile()); err == nil {
+		json.Unmarshal(data, &state)
+	}
+	result := resolveConfigSync(local, info.ModTime(), remote, remoteMod, state)
+	switch result {
+	case _syncSent, _syncSentConflict:
+		if result == _syncSentConflict {
+			if err := ioutil.WriteFile(file+".conflict", remote, 0644); err != nil {
+				return "", err
+			}
+		}
+		if err := s.put(ctx, _syncConfig, local); err != nil {
+			return "", err
+		}
+	case _syncReceived, _syncReceivedConflict:
+		if _, err := parseConfig(string(remote)); err != nil {
+			return "", fmt.Errorf("remote %s: %v", _syncConfig, err)
+		}
+		if result == _syncReceivedConflict {
+			if err := ioutil.WriteFile(file+".conflict", local, 0644); err != nil {
+				return "", err
+			}
+		}
+		if err := ioutil.WriteFile(file, remote, 0644); err != nil {
+			return "", err
+		}
+	}
+
+	synced := local
+	if result == _syncReceived || result == _syncReceivedConflict {
+		synced = remote
+	}
+	data, err := json.Marshal(syncState{Config: time.Now(), ConfigHash: configHash(synced)})
+	if err != nil {
+		return "", err
+	}
+	return result, ioutil.WriteFile(getSyncStateFile(), append(data, '\n'), 0644)
+}
+
+// resolveConfigSync returns how the local and the remote config file, last
+// modified at localMod and remoteMod, are synced given the state of the last
+// sync. A missing remote file is nil, a zero remoteMod is unknown.
+func resolveConfigSync(local []byte, localMod time.Time, remote []byte, remoteMod time.Time, last syncState) string {
+	switch {
+	case bytes.Equal(local, remote):
+		return _syncSame
+	case remote == nil:
+		return _syncSent
+	}
+
+	sent := localMod.After(remoteMod)
+	conflict := false
+	if !last.Config.IsZero() {
+		// The content synced last tells which side changed, the times are
+		// only used by syncs of older versions. A remote file without a
+		// time of modification may have changed.
+		localChanged := localMod.After(last.Config)
+		remoteChanged := remoteMod.IsZero() || remoteMod.After(last.Config)
+		if last.ConfigHash != "" {
+			localChanged = configHash(local) != last.ConfigHash
+			remoteChanged = configHash(remote) != last.ConfigHash
+		}
+		conflict = localChanged && remoteChanged
+		if localChanged != remoteChanged {
+			sent = localChanged
+		}
+	}
+
+	switch {
+	case sent && conflict:
+		return _syncSentConflict
+	case sent:
+		return _syncSent
+	case conflict:
+		return _syncReceivedConflict
+	}
+	return _syncReceived
+}
+
+// configHash returns the hash of the content of a config file kept in the
+// state of the last sync.
+func configHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// get returns the content of the remote file name and its time of
+// modification, nil if it does not exist.
+func (s syncConfig) get(ctx context.Context, name string) ([]byte, time.Time, error) {
+	resp, err := s.do(ctx, http.MethodGet, name, nil)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, time.Time{}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, time.Time{}, fmt.Errorf("GET %s: %s", name, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	mod, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return data, mod, nil
+}
+
+// put replaces the content of the remote file name.
+func (s syncConfig) put(ctx context.Context, name string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, name, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("PUT %s: %s", name, resp.Status)
+	}
+	return nil
+}
+
+// do sends the request for the remote file name, with the credentials of
+// the config file if any.
+func (s syncConfig) do(ctx context.Context, method, name string, body []byte) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, _hookTimeout)
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(s.url, "/")+"/"+name, bytes.NewReader(body))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if s.user != "" {
+		req.SetBasicAuth(s.user, s.password)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = cancelBody{resp.Body, cancel}
+	return resp, nil
+}
+
+// cancelBody is a response body whose request is cancelled when it is
+// closed, so that the body can be read after the request returned.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
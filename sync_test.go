@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeHistory(t *testing.T) {
+	day := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	a := historyEntry{Kind: _historyTimer, Start: day, Duration: 60}
+	b := historyEntry{Kind: _historyPomodoro, Start: day.Add(time.Hour), Duration: 1500}
+	c := historyEntry{Kind: _historyTimer, Label: "tea", Start: day.Add(2 * time.Hour), Duration: 180}
+
+	added, sent, merged := mergeHistory([]historyEntry{a, c}, []historyEntry{b, c, b})
+	if len(added) != 1 || added[0] != b {
+		t.Errorf("want added %v got %v", []historyEntry{b}, added)
+	}
+	if sent != 1 {
+		t.Errorf("want 1 sent got %d", sent)
+	}
+	want := []historyEntry{a, b, b, c}
+	if len(merged) != len(want) {
+		t.Fatalf("want merged %v got %v", want, merged)
+	}
+	for i := range want {
+		if merged[i] != want[i] {
+			t.Errorf("merged %d: want %v got %v", i, want[i], merged[i])
+		}
+	}
+
+	if added, sent, _ := mergeHistory([]historyEntry{a}, []historyEntry{a}); len(added) != 0 || sent != 0 {
+		t.Errorf("same histories: want nothing to sync got %v added and %d sent", added, sent)
+	}
+}
+
+func TestResolveConfigSync(t *testing.T) {
+	last := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	before, after, later := last.Add(-time.Hour), last.Add(time.Hour), last.Add(2*time.Hour)
+	local, remote := []byte("volume = 50\n"), []byte("volume = 80\n")
+
+	for _, test := range []struct {
+		name                string
+		remote              []byte
+		localMod, remoteMod time.Time
+		last                time.Time
+		want                string
+	}{
+		{"same", local, after, later, last, _syncSame},
+		{"no remote", nil, after, time.Time{}, last, _syncSent},
+		{"local changed", remote, after, before, last, _syncSent},
+		{"remote changed", remote, before, after, last, _syncReceived},
+		{"first sync", remote, later, after, time.Time{}, _syncSent},
+		{"both changed local newer", remote, later, after, last, _syncSentConflict},
+		{"both changed remote newer", remote, after, later, last, _syncReceivedConflict},
+		// Without Last-Modified the remote file is taken as changed
+		{"no remote time", remote, before, time.Time{}, last, _syncReceived},
+		{"no remote time local changed", remote, after, time.Time{}, last, _syncSentConflict},
+	} {
+		state := syncState{Config: test.last}
+		if got := resolveConfigSync(local, test.localMod, test.remote, test.remoteMod, state); got != test.want {
+			t.Errorf("%s: want %q got %q", test.name, test.want, got)
+		}
+	}
+
+	// The hash of the config file synced last tells which side changed,
+	// whatever the times of modification
+	synced := []byte("volume = 20\n")
+	for _, test := range []struct {
+		name          string
+		local, remote []byte
+		want          string
+	}{
+		{"local changed", local, synced, _syncSent},
+		{"remote changed", synced, remote, _syncReceived},
+		{"both changed", local, remote, _syncSentConflict},
+	} {
+		state := syncState{Config: last, ConfigHash: configHash(synced)}
+		if got := resolveConfigSync(test.local, before, test.remote, time.Time{}, state); got != test.want {
+			t.Errorf("hash %s: want %q got %q", test.name, test.want, got)
+		}
+	}
+}
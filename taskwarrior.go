@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	errNoTaskwarrior   = errors.New("Taskwarrior is not installed, the task command is not found")
+	errInvalidDuration = errors.New("Invalid ISO 8601 duration")
+)
+
+// name of the command of Taskwarrior, a variable for the tests
+var _taskCommand = "task"
+
+// checkTask returns an error if the task of -task cannot be annotated.
+func checkTask() error {
+	if _, err := exec.LookPath(_taskCommand); err != nil {
+		return errNoTaskwarrior
+	}
+	return nil
+}
+
+// annotateTask processes -task.
+// Annotate the Taskwarrior task id with the time of the timer started at
+// start and expired at end. With task_uda in the config file, the duration
+// is added to that duration UDA of the task as well.
+func (cmd *Cmd) annotateTask(id string, start, end time.Time) error {
+	if _, err := runTask(id, "annotate", taskAnnotation(cmd.label, start, end)); err != nil {
+		return err
+	}
+	uda := cmd.config.taskUDA
+	if uda == "" {
+		return nil
+	}
+
+	out, err := runTask("_get", id+"."+uda)
+	if err != nil {
+		return err
+	}
+	total, err := parseISODuration(strings.TrimSpace(out))
+	if err != nil {
+		return fmt.Errorf("%s of task %s: %v", uda, id, err)
+	}
+	total += end.Sub(start).Round(time.Second)
+	_, err = runTask(id, "modify", uda+":"+formatISODuration(total))
+	return err
+}
+
+// taskAnnotation returns the annotation of the timer labelled label started
+// at start and expired at end.
+func taskAnnotation(label string, start, end time.Time) string {
+	text := fmt.Sprintf("Timed %v from %s to %s", end.Sub(start).Round(time.Second),
+		start.Format("15:04"), end.Format("15:04"))
+	if label != "" {
+		text += ": " + label
+	}
+	return text
+}
+
+// runTask runs Taskwarrior with the arguments without asking for
+// confirmation, and returns its output.
+func runTask(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	ex := exec.Command(_taskCommand, append([]string{"rc.confirmation=off", "rc.verbose=nothing"}, args...)...)
+	ex.Stdout, ex.Stderr = &stdout, &stderr
+	if err := ex.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%v: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// parseISODuration parses the ISO 8601 durations Taskwarrior shows, like
+// PT1H25M or P1DT2H, an empty value is 0.
+func parseISODuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if !strings.HasPrefix(s, "P") || s == "P" || strings.HasSuffix(s, "T") {
+		return 0, errInvalidDuration
+	}
+
+	var d time.Duration
+	units := map[byte]time.Duration{'D': 24 * time.Hour, 'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+	inTime := false
+	n := ""
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == 'T' && !inTime && n == "":
+			inTime = true
+		case c >= '0' && c <= '9':
+			n += string(c)
+		case n != "" && (c == 'D') != inTime && units[c] != 0:
+			v, err := strconv.Atoi(n)
+			if err != nil {
+				return 0, errInvalidDuration
+			}
+			d += time.Duration(v) * units[c]
+			n = ""
+		default:
+			return 0, errInvalidDuration
+		}
+	}
+	if n != "" {
+		return 0, errInvalidDuration
+	}
+	return d, nil
+}
+
+// formatISODuration returns d in whole seconds as an ISO 8601 duration, like
+// PT1H25M.
+func formatISODuration(d time.Duration) string {
+	s := int64(d.Round(time.Second) / time.Second)
+	if s <= 0 {
+		return "PT0S"
+	}
+	var b strings.Builder
+	b.WriteString("PT")
+	for _, u := range []struct {
+		unit int64
+		name string
+	}{{3600, "H"}, {60, "M"}, {1, "S"}} {
+		if s >= u.unit {
+			fmt.Fprintf(&b, "%d%s", s/u.unit, u.name)
+			s %= u.unit
+		}
+	}
+	return b.String()
+}
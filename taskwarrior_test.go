@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseISODuration(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Duration
+		ok   bool
+	}{
+		{"", 0, true},
+		{"PT25M", 25 * time.Minute, true},
+		{"PT1H5M30S", time.Hour + 5*time.Minute + 30*time.Second, true},
+		{"P1DT2H", 26 * time.Hour, true},
+		{"P2D", 48 * time.Hour, true},
+		{"P", 0, false},
+		{"PT", 0, false},
+		{"P1M", 0, false},
+		{"PT1D", 0, false},
+		{"PT25", 0, false},
+		{"25m", 0, false},
+	}
+
+	for _, test := range tests {
+		got, err := parseISODuration(test.s)
+		if ok := err == nil; ok != test.ok || got != test.want {
+			t.Errorf("%q: want %v %v got %v %v", test.s, test.want, test.ok, got, err)
+		}
+	}
+}
+
+func TestFormatISODuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "PT0S"},
+		{25 * time.Minute, "PT25M"},
+		{time.Hour + 5*time.Minute + 30*time.Second, "PT1H5M30S"},
+		{26 * time.Hour, "PT26H"},
+	}
+
+	for _, test := range tests {
+		if got := formatISODuration(test.d); got != test.want {
+			t.Errorf("%v: want %s got %s", test.d, test.want, got)
+		}
+	}
+}
+
+func TestTaskAnnotation(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	if got, want := taskAnnotation("", start, start.Add(25*time.Minute)), "Timed 25m0s from 09:00 to 09:25"; got != want {
+		t.Errorf("want %q got %q", want, got)
+	}
+	if got, want := taskAnnotation("Review", start, start.Add(time.Hour)), "Timed 1h0m0s from 09:00 to 10:00: Review"; got != want {
+		t.Errorf("want %q got %q", want, got)
+	}
+}
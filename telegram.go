@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// _telegramAPI is the Telegram Bot API server, replaced in tests.
+var _telegramAPI = "https://api.telegram.org"
+
+// longest a single request for the replies to the bot waits for one
+const _telegramPoll = 30 * time.Second
+
+// telegramUpdate is an update of the Telegram bot, a message sent to it.
+type telegramUpdate struct {
+	ID      int64 `json:"update_id"`
+	Message struct {
+		Date int64  `json:"date"`
+		Text string `json:"text"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+	} `json:"message"`
+}
+
+// telegram returns whether the end of the timer is sent to Telegram, that is
+// whether the bot token and the chat are configured.
+func (cmd *Cmd) telegram() bool {
+	return cmd.config.telegramToken != "" && cmd.config.telegramChat != ""
+}
+
+// sendTelegram sends the message to the chat with the Telegram bot of the
+// token.
+func sendTelegram(token, chat, text string) error {
+	body, err := json.Marshal(struct {
+		ChatID string `json:"chat_id"`
+		Text   string `json:"text"`
+	}{chat, text})
+	if err != nil {
+		return err
+	}
+	return postHook(_telegramAPI+"/bot"+token+"/sendMessage",
+		http.Header{"Content-Type": {"application/json"}}, body)
+}
+
+// awaitSnooze waits for a reply of /snooze TIME to the Telegram bot after the
+// timer expired, for the telegram_snooze window. It returns the time to
+// snooze for, or 0 if there was no such reply in time.
+func (cmd *Cmd) awaitSnooze() time.Duration {
+	window := cmd.config.telegramSnooze
+	fmt.Printf("Reply /snooze TIME to the Telegram bot within %v to snooze the timer\n", window)
+	ctx, cancel := context.WithTimeout(cmd.ctx, window)
+	defer cancel()
+
+	offset := int64(0)
+	for ctx.Err() == nil {
+		updates, err := telegramUpdates(ctx, cmd.config.telegramToken, offset)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("reading the Telegram replies failed", "err", err)
+				// Do not retry in a busy loop while offline
+				time.Sleep(_hookBackoff)
+			}
+			continue
+		}
+		for _, u := range updates {
+			offset = u.ID + 1
+			if strconv.FormatInt(u.Message.Chat.ID, 10) != cmd.config.telegramChat ||
+				u.Message.Date < cmd.expired.Unix() {
+				continue
+			}
+			if d, ok := parseSnooze(u.Message.Text); ok {
+				fmt.Printf("Snoozed for %v\n", d)
+				return d
+			}
+		}
+	}
+	return 0
+}
+
+// parseSnooze returns the time asked for by the /snooze command in the
+// message, a bare number counts minutes.
+func parseSnooze(text string) (time.Duration, bool) {
+	fields := strings.Fields(text)
+	// In group chats the command may name the bot, like /snooze@timer_bot
+	if len(fields) != 2 || strings.SplitN(fields[0], "@", 2)[0] != "/snooze" {
+		return 0, false
+	}
+	d, err := parseTime(fields[1], time.Minute)
+	return d, err == nil && d > 0
+}
+
+// telegramUpdates returns the updates of the Telegram bot of the token from
+// offset on, waiting for one for up to _telegramPoll.
+func telegramUpdates(ctx context.Context, token string, offset int64) ([]telegramUpdate, error) {
+	query := url.Values{
+		"offset":          {strconv.FormatInt(offset, 10)},
+		"timeout":         {strconv.Itoa(int(_telegramPoll / time.Second))},
+		"allowed_updates": {`["message"]`},
+	}
+	ctx, cancel := context.WithTimeout(ctx, _telegramPoll+_hookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		_telegramAPI+"/bot"+token+"/getUpdates?"+query.Encode(), nil)
+	if err != nil {
+		return nil, redactError(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, redactError(err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool             `json:"ok"`
+		Description string           `json:"description"`
+		Result      []telegramUpdate `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if !result.OK {
+		return nil, fmt.Errorf("Request failed: %s", result.Description)
+	}
+	return result.Result, nil
+}
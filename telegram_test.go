@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseSnooze(t *testing.T) {
+	tests := []struct {
+		text string
+		want time.Duration
+		ok   bool
+	}{
+		{"/snooze 5m", 5 * time.Minute, true},
+		{"/snooze 10", 10 * time.Minute, true},
+		{"/snooze@timer_bot 30s", 30 * time.Second, true},
+		{"/snooze", 0, false},
+		{"/snooze later", 0, false},
+		{"snooze 5m", 0, false},
+	}
+	for _, test := range tests {
+		got, ok := parseSnooze(test.text)
+		if got != test.want || ok != test.ok {
+			t.Errorf("parseSnooze(%q) = %v, %v, want %v, %v", test.text, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestTelegram(t *testing.T) {
+	expired := time.Now()
+	var sent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/botTOKEN/sendMessage":
+			data, _ := io.ReadAll(r.Body)
+			sent = string(data)
+		case "/botTOKEN/getUpdates":
+			// A reply from another chat and one from before the expiry
+			// are ignored
+			fmt.Fprintf(w, `{"ok": true, "result": [
+				{"update_id": 1, "message": {"date": %d, "text": "/snooze 1m", "chat": {"id": 7}}},
+				{"update_id": 2, "message": {"date": %d, "text": "/snooze 2m", "chat": {"id": 42}}},
+				{"update_id": 3, "message": {"date": %d, "text": "/snooze 3m", "chat": {"id": 42}}}
+			]}`, expired.Unix(), expired.Unix()-60, expired.Unix())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer func(api string) { _telegramAPI = api }(_telegramAPI)
+	_telegramAPI = server.URL
+
+	if err := sendTelegram("TOKEN", "42", "Tea"); err != nil {
+		t.Fatalf("sendTelegram failed: %v", err)
+	}
+	if sent != `{"chat_id":"42","text":"Tea"}` {
+		t.Errorf("sendTelegram sent %s", sent)
+	}
+
+	cmd := &Cmd{ctx: context.Background(), expired: expired, config: &config{
+		telegramToken: "TOKEN", telegramChat: "42", telegramSnooze: time.Second,
+	}}
+	if got := cmd.awaitSnooze(); got != 3*time.Minute {
+		t.Errorf("awaitSnooze() = %v, want 3m0s", got)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// cmdTemplates are the templates of the texts shown and sent, nil unless
+// set in the config file or with their flags.
+type cmdTemplates struct {
+	// line showing the progress of a timer, filled in with progressFields
+	progress *template.Template
+	// message of an expired timer, filled in with its timerEvent
+	expired *template.Template
+	// text of the notifications and of the hooks, filled in with the
+	// timerEvent whose .Message is the message of an expired timer
+	notify *template.Template
+}
+
+// progressFields are the fields of the progress template.
+type progressFields struct {
+	Label                    string
+	Percent                  int
+	Passed, Remaining, Total time.Duration
+}
+
+// parseTemplate parses the template text. The function json quotes a value
+// as JSON, like {{json .Label}}, and clock formats a duration, or a number of
+// seconds, as a clock, like {{clock .Remaining}}.
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"clock": func(v interface{}) (string, error) {
+			switch d := v.(type) {
+			case time.Duration:
+				return formatClock(d), nil
+			case float64:
+				return formatClock(time.Duration(d * float64(time.Second))), nil
+			}
+			return "", fmt.Errorf("clock of %T, expected a duration or seconds", v)
+		},
+	}).Parse(text)
+}
+
+// executeTemplate returns the template filled in with the data.
+func executeTemplate(t *template.Template, data interface{}) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// loadTemplates parses the templates of the config file, and the message of
+// -done-msg which replaces the expired_template.
+func (cmd *Cmd) loadTemplates() error {
+	expired := cmd.config.expiredTemplate
+	if cmd.args.doneMsg != "" {
+		expired = cmd.args.doneMsg
+	}
+	for _, t := range []struct {
+		name, text string
+		into       **template.Template
+	}{
+		{"progress_template", cmd.config.progressTemplate, &cmd.templates.progress},
+		{"expired_template", expired, &cmd.templates.expired},
+		{"notify_template", cmd.config.notifyTemplate, &cmd.templates.notify},
+	} {
+		if t.text == "" {
+			continue
+		}
+		parsed, err := parseTemplate(t.name, t.text)
+		if err != nil {
+			return err
+		}
+		*t.into = parsed
+	}
+	return nil
+}
+
+// render returns the template filled in with the data, or the fallback if
+// it fails.
+func render(t *template.Template, data interface{}, fallback string) string {
+	text, err := executeTemplate(t, data)
+	if err != nil {
+		slog.Warn("filling in the template failed", "template", t.Name(), "err", err)
+		return fallback
+	}
+	return text
+}
+
+// doneText returns the message of the timer of the expired event, from the
+// expired template, empty without one.
+func (cmd *Cmd) doneText(e timerEvent) string {
+	if cmd.templates.expired == nil {
+		return ""
+	}
+	return render(cmd.templates.expired, e, "")
+}
+
+// notifyText returns the event with the notify template, if any, filled in
+// as its message.
+func (cmd *Cmd) notifyText(e timerEvent) timerEvent {
+	if cmd.templates.notify != nil {
+		e.Message = render(cmd.templates.notify, e, e.Message)
+	}
+	return e
+}
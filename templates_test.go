@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTemplate(t *testing.T) {
+	tests := []struct {
+		text string
+		data interface{}
+		want string
+	}{
+		{"{{.Label}} {{clock .Remaining}} left", progressFields{Label: "Tea", Remaining: 250 * time.Second}, "Tea 04:10 left"},
+		{"{{.Percent}}% of {{.Total}}", progressFields{Percent: 42, Total: time.Hour}, "42% of 1h0m0s"},
+		{"{{json .Label}} after {{clock .Duration}}", timerEvent{Label: `"green"`, Duration: 3725}, `"\"green\"" after 1:02:05`},
+	}
+	for _, test := range tests {
+		tmpl, err := parseTemplate("test", test.text)
+		if err != nil {
+			t.Fatalf("%q: %v", test.text, err)
+		}
+		if got, err := executeTemplate(tmpl, test.data); err != nil || got != test.want {
+			t.Errorf("%q: want %q got %q, %v", test.text, test.want, got, err)
+		}
+	}
+	if tmpl, _ := parseTemplate("test", "{{clock .Label}}"); render(tmpl, timerEvent{Label: "Tea"}, "fallback") != "fallback" {
+		t.Error("a failed template did not fall back")
+	}
+}
+
+func TestEventTemplates(t *testing.T) {
+	cmd := &Cmd{config: &config{
+		expiredTemplate: "{{.Label}} is ready",
+		notifyTemplate:  "{{.Status}}: {{.Message}}",
+	}, label: "Tea"}
+	if err := cmd.loadTemplates(); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if e := cmd.event(_statusExpired, start, start.Add(time.Minute)); e.text() != "expired: Tea is ready" {
+		t.Errorf("expired: want the notify template of the expired one got %q", e.text())
+	}
+	if e := cmd.event(_statusCancelled, start, start.Add(time.Minute)); e.text() != "cancelled: " {
+		t.Errorf("cancelled: want the notify template alone got %q", e.text())
+	}
+
+	cmd = &Cmd{args: cmdArgs{doneMsg: "{{.Label}} done"}, config: &config{expiredTemplate: "{{.Label}} is ready"}, label: "Tea"}
+	if err := cmd.loadTemplates(); err != nil {
+		t.Fatal(err)
+	}
+	if got := cmd.doneText(cmd.endEvent(_statusExpired, start, start)); got != "Tea done" {
+		t.Errorf("want -done-msg to replace expired_template got %q", got)
+	}
+
+	cmd = &Cmd{config: &config{progressTemplate: "{{.Missing"}}
+	if err := cmd.loadTemplates(); err == nil {
+		t.Error("an invalid template did not fail")
+	}
+}
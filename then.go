@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// brightness in percent the screen is dimmed to, unless configured
+	_dimLevel = 10
+	// placeholder in the commands of the actions replaced with the brightness
+	_placeholderLevel = "{level}"
+)
+
+var (
+	errUnknownAction = withExitCode(_exitInvalidArgs, errors.New("Unknown action, expected "+thenActions()))
+	errNoAction      = errors.New("No command found on the system for the action")
+)
+
+// thenActions returns the actions -then takes, like lock or suspend.
+func thenActions() string {
+	var actions []string
+	for action := range _thenCommands {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	if len(actions) < 2 {
+		return strings.Join(actions, "")
+	}
+	return strings.Join(actions[:len(actions)-1], ", ") + " or " + actions[len(actions)-1]
+}
+
+// checkThen returns errUnknownAction unless the action of -then or -on-start
+// is empty or one of _thenCommands.
+func checkThen(action string) error {
+	if _, ok := _thenCommands[action]; action != "" && !ok {
+		return errUnknownAction
+	}
+	return nil
+}
+
+// thenCommand returns the command of the action, the first of its commands
+// in _thenCommands whose program is available, or nil if none is.
+func thenCommand(action string) []string {
+	for _, command := range _thenCommands[action] {
+		words, err := splitWords(command)
+		if err != nil || len(words) == 0 {
+			continue
+		}
+		if _, err := exec.LookPath(words[0]); err == nil {
+			return words
+		}
+	}
+	return nil
+}
+
+// runAction processes -then and -on-start.
+// Run the command of the action, like locking the screen or dimming it to
+// the dim_level of the config file.
+func (cmd *Cmd) runAction(action string) error {
+	words := thenCommand(action)
+	if words == nil {
+		fmt.Printf("%v: %s\n", errNoAction, action)
+		return errNoAction
+	}
+	level := strconv.Itoa(_dimLevel)
+	if cmd.config.dimLevel > 0 {
+		level = strconv.Itoa(cmd.config.dimLevel)
+	}
+	for i := range words {
+		words[i] = strings.ReplaceAll(words[i], _placeholderLevel, level)
+	}
+
+	slog.Debug("running the action", "action", action, "args", words)
+	if out, err := exec.Command(words[0], words[1:]...).CombinedOutput(); err != nil {
+		if len(out) > 0 {
+			err = fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+		}
+		return fail("Error running the action "+action, err)
+	}
+	return nil
+}
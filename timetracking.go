@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// APIs of the time tracking services, variables for the tests
+var (
+	_togglAPI    = "https://api.track.toggl.com/api/v9"
+	_clockifyAPI = "https://api.clockify.me/api/v1"
+)
+
+// togglConfig is the Toggl Track workspace labelled timers are added to as
+// time entries, in the project if it is not 0.
+type togglConfig struct {
+	token     string
+	workspace int
+	project   int
+}
+
+// clockifyConfig is the Clockify workspace labelled timers are added to as
+// time entries, in the project if it is not empty.
+type clockifyConfig struct {
+	key       string
+	workspace string
+	project   string
+}
+
+// sendTimeEntries adds the labelled timer started at start and expired at
+// end as a time entry to the time tracking services of the config file.
+func (cmd *Cmd) sendTimeEntries(start, end time.Time) {
+	if cmd.label == "" {
+		return
+	}
+	label := cmd.label
+	if t := cmd.config.toggl; t.token != "" && t.workspace != 0 {
+		cmd.sendHook("toggl", func() error { return sendToggl(t, label, start, end) })
+	}
+	if c := cmd.config.clockify; c.key != "" && c.workspace != "" {
+		cmd.sendHook("clockify", func() error { return sendClockify(c, label, start, end) })
+	}
+}
+
+// sendToggl adds the time entry described by label from start until end to
+// the Toggl Track workspace.
+func sendToggl(t togglConfig, label string, start, end time.Time) error {
+	body, err := json.Marshal(struct {
+		CreatedWith string    `json:"created_with"`
+		Description string    `json:"description"`
+		Workspace   int       `json:"workspace_id"`
+		Project     int       `json:"project_id,omitempty"`
+		Start       time.Time `json:"start"`
+		Stop        time.Time `json:"stop"`
+		Duration    int64     `json:"duration"`
+	}{"timer", label, t.workspace, t.project, start.UTC().Truncate(time.Second), end.UTC().Truncate(time.Second),
+		int64(end.Sub(start).Round(time.Second) / time.Second)})
+	if err != nil {
+		return err
+	}
+	// The API token is the user of the basic authentication
+	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(t.token+":api_token"))
+	return postHook(fmt.Sprintf("%s/workspaces/%d/time_entries", _togglAPI, t.workspace), http.Header{
+		"Content-Type": {"application/json"}, "Authorization": {auth},
+	}, body)
+}
+
+// sendClockify adds the time entry described by label from start until end
+// to the Clockify workspace.
+func sendClockify(c clockifyConfig, label string, start, end time.Time) error {
+	body, err := json.Marshal(struct {
+		Description string    `json:"description"`
+		Project     string    `json:"projectId,omitempty"`
+		Start       time.Time `json:"start"`
+		End         time.Time `json:"end"`
+	}{label, c.project, start.UTC().Truncate(time.Second), end.UTC().Truncate(time.Second)})
+	if err != nil {
+		return err
+	}
+	return postHook(fmt.Sprintf("%s/workspaces/%s/time-entries", _clockifyAPI, c.workspace), http.Header{
+		"Content-Type": {"application/json"}, "X-Api-Key": {c.key},
+	}, body)
+}
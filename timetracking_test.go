@@ -0,0 +1,45 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSendTimeEntries(t *testing.T) {
+	type request struct{ path, auth, key, body string }
+	var got []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		got = append(got, request{r.URL.Path, r.Header.Get("Authorization"), r.Header.Get("X-Api-Key"), string(data)})
+	}))
+	defer server.Close()
+	defer func(toggl, clockify string) { _togglAPI, _clockifyAPI = toggl, clockify }(_togglAPI, _clockifyAPI)
+	_togglAPI, _clockifyAPI = server.URL+"/toggl", server.URL+"/clockify"
+
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	end := start.Add(25 * time.Minute)
+	if err := sendToggl(togglConfig{token: "secret", workspace: 42}, "Review", start, end); err != nil {
+		t.Fatal(err)
+	}
+	if err := sendClockify(clockifyConfig{key: "key", workspace: "ws", project: "p1"}, "Review", start, end); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []request{
+		{"/toggl/workspaces/42/time_entries", "Basic c2VjcmV0OmFwaV90b2tlbg==", "",
+			`{"created_with":"timer","description":"Review","workspace_id":42,"start":"2024-03-01T09:00:00Z","stop":"2024-03-01T09:25:00Z","duration":1500}`},
+		{"/clockify/workspaces/ws/time-entries", "", "key",
+			`{"description":"Review","projectId":"p1","start":"2024-03-01T09:00:00Z","end":"2024-03-01T09:25:00Z"}`},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("want %d requests got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("want %+v got %+v", want[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	errInvalidTone = errors.New("Tone must be of the format FREQUENCYhz[:TIME]")
+)
+
+const (
+	// sample rate of synthesized tones
+	_toneSampleRate = 44100
+	// duration of a tone when none is given
+	_toneDuration = time.Second
+	// longest tone which can be synthesized
+	_toneMaxDuration = time.Minute
+	// time over which a tone ramps up and down, avoiding clicks
+	_toneRamp = 10 * time.Millisecond
+	// amplitude of a tone relative to full scale
+	_toneAmplitude = 0.5
+	// prefix of the name under which a tone is played, it can not clash with
+	// the name of a sound as those can not contain a colon
+	_toneSoundPrefix = "tone:"
+	// tone of the beeps counting down the last seconds of a timer
+	_beepTone = "880hz:150ms"
+)
+
+// parseTone parses the tone specification FREQUENCYhz[:TIME], like 880hz:2s,
+// where the hz suffix is optional.
+func parseTone(s string) (float64, time.Duration, error) {
+	spec := strings.ToLower(strings.TrimSpace(s))
+	freqSpec, durSpec := spec, ""
+	if i := strings.Index(spec, ":"); i >= 0 {
+		freqSpec, durSpec = spec[:i], spec[i+1:]
+	}
+
+	freq, err := strconv.ParseFloat(strings.TrimSuffix(freqSpec, "hz"), 64)
+	if err != nil || freq < 20 || freq > 20000 {
+		return 0, 0, errInvalidTone
+	}
+
+	d := _toneDuration
+	if durSpec != "" {
+		if d, err = time.ParseDuration(durSpec); err != nil || d <= 0 || d > _toneMaxDuration {
+			return 0, 0, errInvalidTone
+		}
+	}
+
+	return freq, d, nil
+}
+
+// synthesizeTone returns a 16 bit mono WAV file of a sine wave of the
+// frequency freq lasting d.
+func synthesizeTone(freq float64, d time.Duration) *wavFile {
+	n := int(d.Seconds() * _toneSampleRate)
+	ramp := int(_toneRamp.Seconds() * _toneSampleRate)
+	if ramp > n/2 {
+		ramp = n / 2
+	}
+
+	w := &wavFile{channels: 1, sampleRate: _toneSampleRate, bitsPerSample: 16}
+	w.data = make([]byte, n*2)
+	for i := 0; i < n; i++ {
+		gain := _toneAmplitude
+		if i < ramp {
+			gain *= float64(i) / float64(ramp)
+		} else if n-i < ramp {
+			gain *= float64(n-i) / float64(ramp)
+		}
+
+		v := gain * math.Sin(2*math.Pi*freq*float64(i)/_toneSampleRate)
+		binary.LittleEndian.PutUint16(w.data[i*2:], uint16(int16(v*math.MaxInt16)))
+	}
+
+	return w
+}
+
+// toneFile writes the tone described by spec to a WAV file in the temporary
+// directory and returns its location. The file is named after the tone and
+// left in place, so that a player started in the background can still read
+// it after the process exits.
+func toneFile(spec string) (string, error) {
+	freq, d, err := parseTone(spec)
+	if err != nil {
+		return "", fail("Invalid tone "+spec, err)
+	}
+
+	name := fmt.Sprintf("timer-tone-%shz-%s.wav", strconv.FormatFloat(freq, 'f', -1, 64), d)
+	file := filepath.Join(os.TempDir(), name)
+
+	f, err := os.Create(file)
+	if err != nil {
+		return "", fail("Error creating the tone file", err)
+	}
+	if err := synthesizeTone(freq, d).write(f); err != nil {
+		f.Close()
+		return "", fail("Error writing the tone file", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fail("Error writing the tone file", err)
+	}
+
+	return file, nil
+}
+
+// addTone synthesizes the tone of the specification and adds it to the sounds
+// under a name starting with _toneSoundPrefix, so that it is played like a
+// sound of the library. The name is returned.
+func (cmd *Cmd) addTone(spec string) (string, error) {
+	file, err := toneFile(spec)
+	if err != nil {
+		return "", err
+	}
+	name := _toneSoundPrefix + spec
+	cmd.sounds[name] = file
+	return name, nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTone(t *testing.T) {
+	tests := []struct {
+		in   string
+		freq float64
+		d    time.Duration
+		err  error
+	}{
+		{"880hz:2s", 880, 2 * time.Second, nil},
+		{"440Hz", 440, time.Second, nil},
+		{"1000:500ms", 1000, 500 * time.Millisecond, nil},
+		{"5hz", 0, 0, errInvalidTone},
+		{"beep", 0, 0, errInvalidTone},
+		{"880hz:0s", 0, 0, errInvalidTone},
+		{"880hz:loud", 0, 0, errInvalidTone},
+	}
+
+	for _, tt := range tests {
+		freq, d, err := parseTone(tt.in)
+		if freq != tt.freq || d != tt.d || err != tt.err {
+			t.Errorf("%s: want %v, %v, %v got %v, %v, %v", tt.in, tt.freq, tt.d, tt.err, freq, d, err)
+		}
+	}
+}
+
+func TestSynthesizeTone(t *testing.T) {
+	w := synthesizeTone(440, 500*time.Millisecond)
+	if got, want := len(w.data), _toneSampleRate; got != want {
+		t.Errorf("want %d bytes got %d", want, got)
+	}
+	if w.data[0] != 0 || w.data[1] != 0 {
+		t.Error("tone does not start silent")
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var (
+	errTracking    = withExitCode(_exitInvalidArgs, errors.New("A session is tracked already, stop it first with track stop"))
+	errNotTracking = withExitCode(_exitInvalidArgs, errors.New("No session is tracked, start one with track start LABEL"))
+)
+
+// trackState is the work session tracked until track stop.
+type trackState struct {
+	Label string    `json:"label"`
+	Start time.Time `json:"start"`
+}
+
+// getTrackFile returns the location of the state of the tracked session.
+func getTrackFile() string {
+	return filepath.Join(getConfigDir(), "track.json")
+}
+
+// loadTrack returns the tracked session, an error satisfying os.IsNotExist
+// if there is none.
+func loadTrack() (trackState, error) {
+	var state trackState
+	data, err := ioutil.ReadFile(getTrackFile())
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("%s: %v", getTrackFile(), err)
+	}
+	return state, nil
+}
+
+// trackStart processes the command (track start LABEL).
+// Start tracking a work session labelled LABEL, which lasts until track stop.
+func (cmd *Cmd) trackStart(args []string) error {
+	if len(args) != 1 {
+		fmt.Println("Expected the LABEL of the session to track, like track start writing")
+		return errInvalidArgs
+	}
+	if state, err := loadTrack(); err == nil {
+		fmt.Printf("Tracking %s since %s\n", state.Label, state.Start.Format("15:04"))
+		fmt.Println(errTracking)
+		return errTracking
+	} else if !os.IsNotExist(err) {
+		return fail("Error reading the tracked session", err)
+	}
+
+	state := trackState{Label: args[0], Start: time.Now()}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fail("Error starting the session", err)
+	}
+	if err := os.MkdirAll(getConfigDir(), 0776); err != nil {
+		return fail("Error starting the session", err)
+	}
+	if err := ioutil.WriteFile(getTrackFile(), append(data, '\n'), 0644); err != nil {
+		return fail("Error starting the session", err)
+	}
+	fmt.Printf("Tracking %s since %s\n", state.Label, state.Start.Format("15:04"))
+	return nil
+}
+
+// trackStop processes the command (track stop).
+// Stop the tracked session and record it in the history.
+func (cmd *Cmd) trackStop(args []string) error {
+	if len(args) != 0 {
+		fmt.Println("Unexpected arguments to track stop")
+		return errInvalidArgs
+	}
+	state, err := loadTrack()
+	if os.IsNotExist(err) {
+		fmt.Println(errNotTracking)
+		return errNotTracking
+	} else if err != nil {
+		return fail("Error reading the tracked session", err)
+	}
+
+	took := time.Since(state.Start)
+	if err := appendHistory(historyEntry{
+		Kind: _historyTrack, Label: state.Label, Start: state.Start, Duration: took.Seconds(), Status: _statusStopped,
+	}); err != nil {
+		return fail("Error recording the session in the history", err)
+	}
+	if err := os.Remove(getTrackFile()); err != nil {
+		return fail("Error stopping the session", err)
+	}
+	fmt.Printf("Tracked %s for %v\n", state.Label, took.Round(time.Second))
+	return nil
+}
+
+// trackStatus processes the command (track).
+// Show the tracked session and how long it lasts so far.
+func (cmd *Cmd) trackStatus(args []string) error {
+	if len(args) != 0 {
+		fmt.Println("Expected track start LABEL, track stop or track alone")
+		return errInvalidArgs
+	}
+	state, err := loadTrack()
+	if os.IsNotExist(err) {
+		fmt.Println("No session is tracked")
+		return nil
+	} else if err != nil {
+		return fail("Error reading the tracked session", err)
+	}
+	fmt.Printf("Tracking %s since %s, for %v\n", state.Label, state.Start.Format("15:04"),
+		time.Since(state.Start).Round(time.Second))
+	return nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// logEvent appends the event of the running timer to the transcript of -log,
+// on a line starting with the time and the label of the timer.
+func (cmd *Cmd) logEvent(format string, args ...interface{}) {
+	if cmd.transcript == nil {
+		return
+	}
+	if err := writeEvent(cmd.transcript, time.Now(), cmd.label, fmt.Sprintf(format, args...)); err != nil {
+		slog.Warn("writing the transcript failed", "err", err)
+	}
+}
+
+// writeEvent writes the line of the event at t of the timer labelled label.
+func writeEvent(w io.Writer, t time.Time, label, event string) error {
+	if label != "" {
+		event = label + ": " + event
+	}
+	_, err := fmt.Fprintf(w, "%s %s\n", t.Format(time.RFC3339), event)
+	return err
+}
+
+// logMilestones logs each quarter of the timer of duration t which passed
+// since the first logged ones, and returns the number of quarters logged.
+func (cmd *Cmd) logMilestones(passed, t time.Duration, logged int) int {
+	for q := logged + 1; q < 4 && passed >= t*time.Duration(q)/4; q++ {
+		cmd.logEvent("%d%% passed, %v remaining", q*25, (t - passed).Round(time.Second))
+		logged = q
+	}
+	return logged
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWriteEvent(t *testing.T) {
+	at := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	var b bytes.Buffer
+	writeEvent(&b, at, "", "started, 4m0s until 09:34:00")
+	writeEvent(&b, at, "Tea", "expired after 4m0s")
+	want := "2024-03-01T09:30:00Z started, 4m0s until 09:34:00\n2024-03-01T09:30:00Z Tea: expired after 4m0s\n"
+	if got := b.String(); got != want {
+		t.Errorf("want %q got %q", want, got)
+	}
+}
+
+func TestLogMilestones(t *testing.T) {
+	var b bytes.Buffer
+	cmd := &Cmd{transcript: &b}
+	tests := []struct {
+		passed time.Duration
+		want   int
+	}{
+		{0, 0},
+		{59 * time.Second, 0},
+		{time.Minute, 1},
+		{3 * time.Minute, 3},
+		{4 * time.Minute, 3},
+	}
+
+	logged := 0
+	for _, test := range tests {
+		if logged = cmd.logMilestones(test.passed, 4*time.Minute, logged); logged != test.want {
+			t.Errorf("%v: want %d got %d", test.passed, test.want, logged)
+		}
+	}
+	if n := bytes.Count(b.Bytes(), []byte("\n")); n != 3 {
+		t.Errorf("want 3 lines got %d: %q", n, b.String())
+	}
+}
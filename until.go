@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	// The time zone database is built in for systems without one, like Windows
+	_ "time/tzdata"
+)
+
+var (
+	errInvalidDate  = withExitCode(_exitInvalidArgs, errors.New("Date must be like 2025-12-25T09:00, 2025-12-25 09:00 or 2025-12-25"))
+	errPastDate     = withExitCode(_exitInvalidArgs, errors.New("Date is in the past"))
+	errInvalidClock = withExitCode(_exitInvalidArgs, errors.New("Time of day must be like 09:00 or 09:00:30"))
+	errInvalidZone  = withExitCode(_exitInvalidArgs, errors.New("Time zone must be like Europe/Berlin, UTC or Local"))
+)
+
+// _dateLayouts are the layouts of a date accepted by -until-date, in local
+// time unless the date has an offset like 2025-12-25T09:00:00+01:00.
+var _dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseDate parses a date of one of the layouts in _dateLayouts, a date
+// without an offset is in the location loc.
+func parseDate(s string, loc *time.Location) (time.Time, error) {
+	for _, layout := range _dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errInvalidDate
+}
+
+// _clockLayouts are the layouts of a time of day accepted by -until.
+var _clockLayouts = []string{"15:04", "15:04:05"}
+
+// splitZone splits the time zone from a date or time of day like
+// 09:00@Europe/Berlin. The location is local time if there is no time zone.
+func splitZone(s string) (string, *time.Location, error) {
+	i := strings.LastIndex(s, "@")
+	if i < 0 {
+		return s, time.Local, nil
+	}
+	loc, err := time.LoadLocation(s[i+1:])
+	if err != nil || s[i+1:] == "" {
+		return "", nil, errInvalidZone
+	}
+	return s[:i], loc, nil
+}
+
+// nextClock returns the next time after now at which the clock in the
+// location loc shows the time of day s. The day is counted on the calendar,
+// so that the time of day is kept when daylight saving time starts or ends
+// in between.
+func nextClock(s string, now time.Time, loc *time.Location) (time.Time, error) {
+	var clock time.Time
+	var err error
+	for _, layout := range _clockLayouts {
+		if clock, err = time.Parse(layout, s); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return time.Time{}, errInvalidClock
+	}
+
+	y, m, d := now.In(loc).Date()
+	for {
+		t := time.Date(y, m, d, clock.Hour(), clock.Minute(), clock.Second(), 0, loc)
+		if t.After(now) {
+			return t, nil
+		}
+		d++
+	}
+}
+
+// formatDays returns d like 3d4h5m10s, counting whole days instead of hours
+// as d.String does.
+func formatDays(d time.Duration) string {
+	days := d / (24 * time.Hour)
+	if days == 0 {
+		return d.String()
+	}
+	d -= days * 24 * time.Hour
+	h, m, s := d/time.Hour, d%time.Hour/time.Minute, d%time.Minute/time.Second
+	return fmt.Sprintf("%dd%dh%dm%ds", days, h, m, s)
+}
+
+// untilDate processes the time of the timer given by -until-date.
+// Count down to the date, in local time unless it has an offset or a time
+// zone.
+func (cmd *Cmd) untilDate() error {
+	date, loc, err := splitZone(cmd.args.untilDate)
+	if err == nil {
+		var end time.Time
+		if end, err = parseDate(date, loc); err == nil {
+			return cmd.countdownTo(end)
+		}
+	}
+	return fail(fmt.Sprintf("Invalid date %s. %v", cmd.args.untilDate, err), err)
+}
+
+// untilClock processes the time of the timer given by -until.
+// Count down to the next time the clock shows the time of day, in local time
+// unless it has a time zone.
+func (cmd *Cmd) untilClock() error {
+	clock, loc, err := splitZone(cmd.args.until)
+	if err == nil {
+		var end time.Time
+		if end, err = nextClock(clock, time.Now(), loc); err == nil {
+			return cmd.countdownTo(end)
+		}
+	}
+	return fail(fmt.Sprintf("Invalid time of day %s. %v", cmd.args.until, err), err)
+}
+
+// countdownTo runs the timer until the time end on the wall clock.
+func (cmd *Cmd) countdownTo(end time.Time) error {
+	if !end.After(time.Now()) {
+		return fail(fmt.Sprintf("Invalid date %s. %v", end.Format(time.RFC3339), errPastDate), errPastDate)
+	}
+
+	// The timer starts at the last whole second before now, so that the times
+	// shown are in whole seconds
+	start := end.Add(-time.Until(end).Truncate(time.Second) - time.Second)
+	fmt.Printf("Timer until %s\n", end.Format("Mon, 02 Jan 2006 15:04:05 MST"))
+	return cmd.countdownBetween(start, end, time.Second)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	loc := time.FixedZone("CET", 3600)
+	tests := []struct {
+		in   string
+		want time.Time
+		err  error
+	}{
+		{"2025-12-25T09:00", time.Date(2025, 12, 25, 9, 0, 0, 0, loc), nil},
+		{"2025-12-25T09:00:30", time.Date(2025, 12, 25, 9, 0, 30, 0, loc), nil},
+		{"2025-12-25 09:00", time.Date(2025, 12, 25, 9, 0, 0, 0, loc), nil},
+		{"2025-12-25", time.Date(2025, 12, 25, 0, 0, 0, 0, loc), nil},
+		{"2025-12-25T09:00:00Z", time.Date(2025, 12, 25, 9, 0, 0, 0, time.UTC), nil},
+		{"2025-12-25T09:00:00+02:00", time.Date(2025, 12, 25, 7, 0, 0, 0, time.UTC), nil},
+		{"2025-13-25", time.Time{}, errInvalidDate},
+		{"25.12.2025", time.Time{}, errInvalidDate},
+		{"tomorrow", time.Time{}, errInvalidDate},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDate(tt.in, loc)
+		if !got.Equal(tt.want) || err != tt.err {
+			t.Errorf("%s: want %v, %v got %v, %v", tt.in, tt.want, tt.err, got, err)
+		}
+	}
+}
+
+func TestFormatDays(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{90 * time.Minute, "1h30m0s"},
+		{24 * time.Hour, "1d0h0m0s"},
+		{76*time.Hour + 5*time.Minute + 10*time.Second, "3d4h5m10s"},
+		{400 * 24 * time.Hour, "400d0h0m0s"},
+	}
+
+	for _, tt := range tests {
+		if got := formatDays(tt.in); got != tt.want {
+			t.Errorf("%v: want %s got %s", tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestNextClock(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		clock string
+		now   time.Time
+		want  time.Time
+		err   error
+	}{
+		{"09:00", time.Date(2025, 6, 1, 8, 0, 0, 0, berlin), time.Date(2025, 6, 1, 9, 0, 0, 0, berlin), nil},
+		{"09:00", time.Date(2025, 6, 1, 9, 0, 0, 0, berlin), time.Date(2025, 6, 2, 9, 0, 0, 0, berlin), nil},
+		{"09:00:30", time.Date(2025, 6, 1, 9, 0, 0, 0, berlin), time.Date(2025, 6, 1, 9, 0, 30, 0, berlin), nil},
+		// daylight saving time starts on March 30 and ends on October 26
+		{"09:00", time.Date(2025, 3, 29, 10, 0, 0, 0, berlin), time.Date(2025, 3, 30, 9, 0, 0, 0, berlin), nil},
+		{"09:00", time.Date(2025, 10, 25, 10, 0, 0, 0, berlin), time.Date(2025, 10, 26, 9, 0, 0, 0, berlin), nil},
+		// the day is the day in the time zone, not the one of now
+		{"09:00", time.Date(2025, 6, 1, 23, 0, 0, 0, time.UTC), time.Date(2025, 6, 2, 9, 0, 0, 0, berlin), nil},
+		{"9am", time.Date(2025, 6, 1, 8, 0, 0, 0, berlin), time.Time{}, errInvalidClock},
+		{"25:00", time.Date(2025, 6, 1, 8, 0, 0, 0, berlin), time.Time{}, errInvalidClock},
+	}
+
+	for _, tt := range tests {
+		got, err := nextClock(tt.clock, tt.now, berlin)
+		if !got.Equal(tt.want) || err != tt.err {
+			t.Errorf("%s at %v: want %v, %v got %v, %v", tt.clock, tt.now, tt.want, tt.err, got, err)
+		}
+	}
+
+	// Across the start of daylight saving time the day is an hour shorter
+	got, _ := nextClock("09:00", time.Date(2025, 3, 29, 10, 0, 0, 0, berlin), berlin)
+	if d := got.Sub(time.Date(2025, 3, 29, 9, 0, 0, 0, berlin)); d != 23*time.Hour {
+		t.Errorf("want 23h from 09:00 to 09:00 the next day got %v", d)
+	}
+}
+
+func TestSplitZone(t *testing.T) {
+	tests := []struct {
+		in   string
+		rest string
+		zone string
+		err  error
+	}{
+		{"09:00", "09:00", "Local", nil},
+		{"09:00@Europe/Berlin", "09:00", "Europe/Berlin", nil},
+		{"2025-12-25T09:00@UTC", "2025-12-25T09:00", "UTC", nil},
+		{"09:00@Mars/Olympus", "", "", errInvalidZone},
+		{"09:00@", "", "", errInvalidZone},
+	}
+
+	for _, tt := range tests {
+		rest, loc, err := splitZone(tt.in)
+		zone := ""
+		if loc != nil {
+			zone = loc.String()
+		}
+		if rest != tt.rest || zone != tt.zone || err != tt.err {
+			t.Errorf("%s: want %s, %s, %v got %s, %s, %v", tt.in, tt.rest, tt.zone, tt.err, rest, zone, err)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"time"
+)
+
+var (
+	errNotWav            = errors.New("Not a WAV file")
+	errUnsupportedFormat = errors.New("Only 8 and 16 bit PCM WAV files are supported")
+)
+
+// _wavFormatPCM is the format tag of uncompressed PCM audio in WAV files.
+const _wavFormatPCM = 1
+
+// wavFile is a PCM WAV file with the samples held in memory.
+type wavFile struct {
+	channels      int
+	sampleRate    int
+	bitsPerSample int
+	// interleaved little endian samples
+	data []byte
+}
+
+// readWav reads a PCM WAV file from r.
+func readWav(r io.Reader) (*wavFile, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) < 12 || string(b[0:4]) != "RIFF" || string(b[8:12]) != "WAVE" {
+		return nil, errNotWav
+	}
+
+	w := &wavFile{}
+	foundFmt := false
+	for pos := 12; pos+8 <= len(b); {
+		id := string(b[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(b[pos+4 : pos+8]))
+		pos += 8
+		if pos+size > len(b) {
+			size = len(b) - pos
+		}
+		chunk := b[pos : pos+size]
+
+		switch id {
+		case "fmt ":
+			if len(chunk) < 16 {
+				return nil, errNotWav
+			}
+			if binary.LittleEndian.Uint16(chunk[0:2]) != _wavFormatPCM {
+				return nil, errUnsupportedFormat
+			}
+			w.channels = int(binary.LittleEndian.Uint16(chunk[2:4]))
+			w.sampleRate = int(binary.LittleEndian.Uint32(chunk[4:8]))
+			w.bitsPerSample = int(binary.LittleEndian.Uint16(chunk[14:16]))
+			foundFmt = true
+		case "data":
+			w.data = chunk
+		}
+
+		// Chunks are padded to an even size
+		pos += size + size%2
+	}
+
+	if !foundFmt || w.data == nil || w.channels == 0 {
+		return nil, errNotWav
+	}
+	if w.bitsPerSample != 8 && w.bitsPerSample != 16 {
+		return nil, errUnsupportedFormat
+	}
+
+	return w, nil
+}
+
+// write writes the WAV file to wr.
+func (w *wavFile) write(wr io.Writer) error {
+	blockAlign := w.channels * w.bitsPerSample / 8
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+len(w.data)))
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))
+	binary.Write(&buf, binary.LittleEndian, uint16(_wavFormatPCM))
+	binary.Write(&buf, binary.LittleEndian, uint16(w.channels))
+	binary.Write(&buf, binary.LittleEndian, uint32(w.sampleRate))
+	binary.Write(&buf, binary.LittleEndian, uint32(w.sampleRate*blockAlign))
+	binary.Write(&buf, binary.LittleEndian, uint16(blockAlign))
+	binary.Write(&buf, binary.LittleEndian, uint16(w.bitsPerSample))
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(w.data)))
+	buf.Write(w.data)
+
+	_, err := wr.Write(buf.Bytes())
+	return err
+}
+
+// fadeIn ramps the volume of the samples linearly from 0 to full over the
+// duration d.
+func (w *wavFile) fadeIn(d time.Duration) {
+	sampleSize := w.bitsPerSample / 8
+	frameSize := w.channels * sampleSize
+	rampFrames := int(d.Seconds() * float64(w.sampleRate))
+
+	for frame := 0; frame < rampFrames && (frame+1)*frameSize <= len(w.data); frame++ {
+		gain := float64(frame) / float64(rampFrames)
+		for ch := 0; ch < w.channels; ch++ {
+			pos := frame*frameSize + ch*sampleSize
+			if sampleSize == 1 {
+				// 8 bit samples are unsigned and centered at 128
+				v := float64(int(w.data[pos])-128) * gain
+				w.data[pos] = byte(int(v) + 128)
+			} else {
+				v := float64(int16(binary.LittleEndian.Uint16(w.data[pos:]))) * gain
+				binary.LittleEndian.PutUint16(w.data[pos:], uint16(int16(v)))
+			}
+		}
+	}
+}
+
+// scale multiplies the samples by gain, clipping them to the range of the
+// sample size.
+func (w *wavFile) scale(gain float64) {
+	sampleSize := w.bitsPerSample / 8
+	for pos := 0; pos+sampleSize <= len(w.data); pos += sampleSize {
+		if sampleSize == 1 {
+			v := math.Round(float64(int(w.data[pos])-128) * gain)
+			w.data[pos] = byte(int(math.Max(-128, math.Min(127, v))) + 128)
+		} else {
+			v := math.Round(float64(int16(binary.LittleEndian.Uint16(w.data[pos:]))) * gain)
+			v = math.Max(math.MinInt16, math.Min(math.MaxInt16, v))
+			binary.LittleEndian.PutUint16(w.data[pos:], uint16(int16(v)))
+		}
+	}
+}
+
+// fadeWavFile writes a copy of the WAV file in to out which fades in over
+// the duration d.
+func fadeWavFile(in, out string, d time.Duration) error {
+	return editWavFile(in, out, func(w *wavFile) { w.fadeIn(d) })
+}
+
+// scaleWavFile writes a copy of the WAV file in to out whose volume is
+// scaled by gain.
+func scaleWavFile(in, out string, gain float64) error {
+	return editWavFile(in, out, func(w *wavFile) { w.scale(gain) })
+}
+
+// editWavFile writes a copy of the WAV file in to out with the samples
+// changed by edit.
+func editWavFile(in, out string, edit func(*wavFile)) error {
+	f, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w, err := readWav(f)
+	if err != nil {
+		return err
+	}
+	edit(w)
+
+	var buf bytes.Buffer
+	if err := w.write(&buf); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(out, buf.Bytes(), 0644)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestWavFadeIn(t *testing.T) {
+	w := &wavFile{channels: 1, sampleRate: 4, bitsPerSample: 16}
+	for i := 0; i < 8; i++ {
+		w.data = append(w.data, 0, 0)
+		binary.LittleEndian.PutUint16(w.data[i*2:], 1000)
+	}
+
+	var buf bytes.Buffer
+	if err := w.write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readWav(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got.fadeIn(time.Second)
+	want := []int16{0, 250, 500, 750, 1000, 1000, 1000, 1000}
+	for i, v := range want {
+		if s := int16(binary.LittleEndian.Uint16(got.data[i*2:])); s != v {
+			t.Errorf("sample %d: want %d got %d", i, v, s)
+		}
+	}
+}
+
+func TestWavScale(t *testing.T) {
+	w := &wavFile{channels: 2, sampleRate: 4, bitsPerSample: 16}
+	for _, v := range []int16{1000, -1000, 20000, -20000} {
+		w.data = binary.LittleEndian.AppendUint16(w.data, uint16(v))
+	}
+
+	w.scale(0.5)
+	want := []int16{500, -500, 10000, -10000}
+	for i, v := range want {
+		if s := int16(binary.LittleEndian.Uint16(w.data[i*2:])); s != v {
+			t.Errorf("sample %d: want %d got %d", i, v, s)
+		}
+	}
+
+	// Samples are clipped instead of wrapping around
+	w.scale(4)
+	want = []int16{2000, -2000, 32767, -32768}
+	for i, v := range want {
+		if s := int16(binary.LittleEndian.Uint16(w.data[i*2:])); s != v {
+			t.Errorf("sample %d: want %d got %d", i, v, s)
+		}
+	}
+
+	w8 := &wavFile{channels: 1, sampleRate: 4, bitsPerSample: 8, data: []byte{128, 228, 28, 255}}
+	w8.scale(0.5)
+	if want := []byte{128, 178, 78, 192}; !bytes.Equal(w8.data, want) {
+		t.Errorf("8 bit samples: want %v got %v", want, w8.data)
+	}
+}
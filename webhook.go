@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	neturl "net/url"
+	"time"
+)
+
+const (
+	// time allowed for each attempt to send a hook
+	_hookTimeout = 10 * time.Second
+	// number of attempts to send a hook, the wait between them doubles from
+	// _hookBackoff
+	_hookAttempts = 3
+	_hookBackoff  = time.Second
+)
+
+// timerEvent is the end of a timer sent to the notifiers, when it expired or
+// was cancelled. The duration is in seconds. The message replaces the text
+// telling how the timer ended, like the next timer of a batch.
+type timerEvent struct {
+	Label      string    `json:"label,omitempty"`
+	Duration   float64   `json:"duration"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Status     string    `json:"status"`
+	Message    string    `json:"message,omitempty"`
+}
+
+// event returns the event of the timer started at start and expiring at end
+// which ended now with the status. Its message is the one of the templates,
+// if any.
+func (cmd *Cmd) event(status string, start, end time.Time) timerEvent {
+	e := cmd.endEvent(status, start, end)
+	if status == _statusExpired {
+		e.Message = cmd.doneText(e)
+	}
+	return cmd.notifyText(e)
+}
+
+// endEvent returns the event of the timer started at start and expiring at
+// end which ended now with the status, without message.
+func (cmd *Cmd) endEvent(status string, start, end time.Time) timerEvent {
+	return timerEvent{
+		Label:      cmd.label,
+		Duration:   end.Sub(start).Seconds(),
+		StartedAt:  start,
+		FinishedAt: time.Now(),
+		Status:     status,
+	}
+}
+
+// sendHooks sends the end of the timer to the notifiers enabled by their own
+// flags or settings in the background, the process waits for them before it
+// exits. Those of -notify-via get the notification of an expired timer
+// instead.
+func (cmd *Cmd) sendHooks(status string, start, end time.Time) {
+	cmd.runPlugins(status, start, end)
+	cmd.exportOTLP(status, start, end)
+	if cmd.mqtt != nil {
+		cmd.sendHook("mqtt", func() error {
+			cmd.publishMQTT(status, start, end)
+			return nil
+		})
+	}
+
+	if cmd.args.task != "" && status == _statusExpired {
+		cmd.sendHook("taskwarrior", func() error { return cmd.annotateTask(cmd.args.task, start, end) })
+	}
+	if status == _statusExpired {
+		cmd.sendTimeEntries(start, end)
+		if cmd.config.orgFile != "" {
+			cmd.sendHook("org", func() error { return cmd.appendOrg(start, end) })
+		}
+	}
+
+	e := cmd.event(status, start, end)
+	if url := cmd.pushgateway(); url != "" {
+		cmd.sendHook("pushgateway", func() error { return pushMetrics(url, e) })
+	}
+	via := make(map[string]bool)
+	if cmd.args.notify && status == _statusExpired {
+		for _, name := range cmd.notifyVia() {
+			via[name] = true
+		}
+	}
+	for _, name := range _hookFlags {
+		n, err := _notifiers[name](cmd)
+		if err != nil || via[name] {
+			// Not enabled, or sent as the notification
+			continue
+		}
+		cmd.sendHook(name, func() error { return n.send(e) })
+	}
+}
+
+// sendHook runs send in the background, a failure is logged.
+func (cmd *Cmd) sendHook(name string, send func() error) {
+	cmd.hooks.Add(1)
+	go func() {
+		defer cmd.hooks.Done()
+		if err := send(); err != nil {
+			slog.Warn("sending the hook failed", "hook", name, "err", err)
+		}
+	}()
+}
+
+// text returns the message of the event, or the one telling how the timer
+// ended along with its label if it has one.
+func (e timerEvent) text() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	if e.Label != "" {
+		return e.Label + ": " + e.body()
+	}
+	return e.body()
+}
+
+// title returns the title of the notification of the event, with the label
+// of the timer if it has one.
+func (e timerEvent) title() string {
+	if e.Label != "" {
+		return tr(_msgNotifyTitle) + ": " + e.Label
+	}
+	return tr(_msgNotifyTitle)
+}
+
+// body returns the message of the event, or the one telling how the timer
+// ended, for the services showing the label in the title.
+func (e timerEvent) body() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	if e.Status == _statusCancelled {
+		return tr(_msgInterrupted)
+	}
+	return tr(_msgNotifyText)
+}
+
+// webhookTemplate returns the template of the webhook body given with
+// -webhook-template or in the config file, if any.
+func (cmd *Cmd) webhookTemplate() string {
+	if cmd.args.webhookTemplate != "" {
+		return cmd.args.webhookTemplate
+	}
+	return cmd.config.webhookTemplate
+}
+
+// webhookBody returns the body of the webhook sent for the event, the event
+// as JSON or the template filled in with its fields.
+func webhookBody(tmpl string, e timerEvent) ([]byte, error) {
+	if tmpl == "" {
+		return json.Marshal(e)
+	}
+
+	t, err := parseTemplate("webhook", tmpl)
+	if err != nil {
+		return nil, err
+	}
+	body, err := executeTemplate(t, e)
+	return []byte(body), err
+}
+
+// sendWebhook posts the body made from the template for the event to the
+// URL.
+func sendWebhook(url, tmpl string, e timerEvent) error {
+	body, err := webhookBody(tmpl, e)
+	if err != nil {
+		return err
+	}
+	header := http.Header{"Content-Type": {"text/plain; charset=utf-8"}}
+	if json.Valid(body) {
+		header.Set("Content-Type", "application/json")
+	}
+	return postHook(url, header, body)
+}
+
+// postHook posts the body with the header to the URL. Failed attempts are
+// retried unless the server rejected the request.
+func postHook(url string, header http.Header, body []byte) error {
+	wait := _hookBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := postOnce(url, header, body)
+		if err == nil || !retry || attempt == _hookAttempts {
+			return err
+		}
+		slog.Debug("retrying the hook", "url", redactURL(url), "attempt", attempt, "err", err)
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// postOnce makes a single attempt to post the body to the URL, it returns
+// whether a failed attempt is worth retrying.
+func postOnce(url string, header http.Header, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), _hookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, redactError(err)
+	}
+	req.Header = header
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return true, redactError(err)
+	}
+	resp.Body.Close()
+	slog.Debug("hook response", "url", redactURL(url), "status", resp.Status)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("Request failed: %s", resp.Status)
+}
+
+// redactURL returns the URL without its path, query and credentials, which
+// may hold secrets like the token of a Telegram bot or of a Slack webhook,
+// so that it can be logged.
+func redactURL(s string) string {
+	u, err := neturl.Parse(s)
+	if err != nil {
+		return "invalid URL"
+	}
+	redacted := neturl.URL{Scheme: u.Scheme, Host: u.Host}
+	if u.Path != "" && u.Path != "/" || u.RawQuery != "" {
+		redacted.Path = "/..."
+	}
+	return redacted.String()
+}
+
+// redactError returns err with the URL of a failed request redacted, as
+// *url.Error has it in its message.
+func redactError(err error) error {
+	var u *neturl.Error
+	if !errors.As(err, &u) {
+		return err
+	}
+	return &neturl.Error{Op: u.Op, URL: redactURL(u.URL), Err: u.Err}
+}